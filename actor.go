@@ -33,14 +33,19 @@ type Actor struct {
 	UseDir    Direction   // Direction where other actors interact with this actor
 
 	act       *Action
+	anim      AnimationPlayer
 	callbacks []*ScriptCallback
+	events    map[string]*ScriptCallback
 	costume   *Costume
 	dialog    *Dialog
 	ego       bool
 	inventory []*Object
 	lookAt    Direction
 	pos       Positionf
+	scale     float32
 	speed     Positionf
+	zones     []zone // The walkboxes and triggers the actor was in at the previous frame.
+	zonesRoom *Room  // The room where the zones were computed.
 }
 
 // NewActor creates a new actor with the given ID and name.
@@ -53,6 +58,7 @@ func NewActor(name string) *Actor {
 
 		Name:  name,
 		pos:   DefaultActorPosition.ToPosf(),
+		scale: 1,
 		speed: DefaultActorSpeed,
 	}
 }
@@ -102,14 +108,19 @@ func (a *Actor) Do(action *Action) Future {
 	if a.act != nil {
 		a.act.Cancel()
 	}
+	a.anim.Stop()
 	a.act = action
 	return a.act.Done()
 }
 
 // Draw renders the actor in the viewport.
 func (a *Actor) Draw(frame *Frame) {
+	a.scale = a.walkBoxScale()
+	if a.Room != nil {
+		a.anim.Tint(a.Room.lightAt(a.pos))
+	}
 	if a.act == nil {
-		a.act = Standing(a.lookAt)
+		a.Do(Standing(a.lookAt))
 	}
 
 	if a.act.RunFrame(frame, a) {
@@ -119,7 +130,7 @@ func (a *Actor) Draw(frame *Frame) {
 
 // Hotspot returns the hotspot of the actor.
 func (a *Actor) Hotspot() Rectangle {
-	return Rectangle{Pos: a.costumePos(), Size: a.Size}
+	return Rectangle{Pos: a.costumePos(), Size: a.scaledSize()}
 }
 
 // FindCallback returns the callback with the given name.
@@ -154,10 +165,21 @@ func (a *Actor) Load(res ResourceLoader) {
 	}
 }
 
-// Locate the actor in the given room, position and direction.
+// OnFrameEvent sets the callback invoked when an animation of the actor emits the given frame
+// event.
+func (a *Actor) OnFrameEvent(event string, cb *ScriptCallback) {
+	if a.events == nil {
+		a.events = make(map[string]*ScriptCallback)
+	}
+	a.events[event] = cb
+}
+
+// Locate the actor in the given room, position and direction. The actor does not enter nor leave
+// any walkbox or trigger by being located, only by moving from there.
 func (a *Actor) Locate(room *Room, pos Position, dir Direction) {
 	a.Room = room
 	a.pos = pos.ToPosf()
+	a.zones, a.zonesRoom = nil, nil
 	a.Do(Standing(dir))
 }
 
@@ -178,18 +200,52 @@ func (a *Actor) ItemUsePosition() (Position, Direction) {
 }
 
 func (a *Actor) costumePos() Position {
-	pos := a.pos.ToPos().Sub(NewPos((a.Size.W / 2), a.Size.H-a.Elev))
+	size := a.scaledSize()
+	elev := int(float32(a.Elev) * a.scale)
+	pos := a.pos.ToPos().Sub(NewPos((size.W / 2), size.H-elev))
 	return pos
 }
 
 func (a *Actor) dialogPos() Position {
-	return a.costumePos().Above(a.Size.H)
+	return a.costumePos().Above(a.scaledSize().H)
+}
+
+// scaledSize returns the size of the actor scaled by the walkbox it stands in.
+func (a *Actor) scaledSize() Size {
+	return NewSize(int(float32(a.Size.W)*a.scale), int(float32(a.Size.H)*a.scale))
+}
+
+// walkBoxScale returns the scale of the actor at its position, as given by the walkbox it stands
+// in or the closest one. Actors are not scaled in rooms with no walkboxes.
+func (a *Actor) walkBoxScale() float32 {
+	if a.Room == nil || a.Room.wbmatrix == nil {
+		return 1
+	}
+	id, _ := a.Room.wbmatrix.walkBoxAt(a.pos)
+	if id == InvalidWalkBox {
+		return 1
+	}
+	return a.Room.wbmatrix.walkBoxes[id].ScaleAt(a.pos)
+}
+
+// walkBoxMask returns the mask of the walkbox the actor stands in, or 0 if it is not standing in any
+// walkbox with a mask.
+func (a *Actor) walkBoxMask() int {
+	if a.Room == nil || a.Room.wbmatrix == nil {
+		return 0
+	}
+	id, included := a.Room.wbmatrix.walkBoxAt(a.pos)
+	if id == InvalidWalkBox || !included {
+		return 0
+	}
+	return a.Room.wbmatrix.walkBoxes[id].mask
 }
 
 // Action is an action that an actor is performing.
 type Action struct {
-	prom *Promise
-	f    func(*Frame, *Actor, *Promise)
+	prom   *Promise
+	f      func(*Frame, *Actor, *Promise)
+	cancel func() // Called when the action is cancelled, if not nil.
 }
 
 // Standing creates a new action that makes an actor stand in the given direction.
@@ -203,7 +259,7 @@ func Standing(dir Direction) *Action {
 				costume = CostumeSpeak(dir)
 			}
 			if cos := a.costume; cos != nil {
-				cos.draw(costume, a.costumePos())
+				cos.draw(&a.anim, costume, a.costumePos(), a.scale)
 			}
 		},
 	}
@@ -227,7 +283,7 @@ func WalkingTo(w []*WayPoint, app *App) *Action {
 			currentTarget := w[0].Position
 
 			if cos := a.costume; cos != nil {
-				cos.draw(CostumeWalk(a.lookAt), a.costumePos())
+				cos.draw(&a.anim, CostumeWalk(a.lookAt), a.costumePos(), a.scale)
 			}
 
 			if a.pos.ToPos() == currentTarget {
@@ -242,7 +298,53 @@ func WalkingTo(w []*WayPoint, app *App) *Action {
 			}
 
 			a.lookAt = a.pos.ToPos().DirectionTo(currentTarget)
-			a.pos = a.pos.Move(currentTarget.ToPosf(), a.speed.Scale(rl.GetFrameTime()))
+			a.pos = a.pos.Move(currentTarget.ToPosf(), a.speed.Scale(rl.GetFrameTime()*a.scale))
+		},
+	}
+}
+
+// Playing creates a new action that makes an actor play the named custom action of its costume in
+// the given direction. Unless it loops, the action is done when the animation is over, and the
+// actor stands looking at the same direction. If connected is not nil, it is completed when the
+// animation shows a frame where the action connects, or when it is over if there is none.
+func Playing(name string, dir Direction, loop bool, connected *Promise) *Action {
+	connect := func() {
+		if connected != nil && !connected.IsCompleted() {
+			connected.Complete()
+		}
+	}
+	return &Action{
+		prom: NewPromise(),
+		f: func(f *Frame, a *Actor, done *Promise) {
+			a.lookAt = dir
+			cos := a.costume
+			if cos == nil {
+				connect()
+				done.Complete()
+				return
+			}
+			act, ok := cos.NamedAction(name, dir)
+			if !ok {
+				connect()
+				done.CompleteWithErrorf("costume action %s not found", name)
+				return
+			}
+			if loop {
+				cos.draw(&a.anim, act, a.costumePos(), a.scale)
+			} else if cos.drawOnce(&a.anim, act, a.costumePos(), a.scale) {
+				connect()
+				done.Complete()
+				cos.draw(&a.anim, CostumeIdle(dir), a.costumePos(), a.scale)
+				return
+			}
+			if a.anim.connects() {
+				connect()
+			}
+		},
+		cancel: func() {
+			if connected != nil && !connected.IsCompleted() {
+				connected.Break()
+			}
 		},
 	}
 }
@@ -253,7 +355,7 @@ func SpeakingTo(dialog Future) *Action {
 		prom: NewPromise(),
 		f: func(f *Frame, a *Actor, done *Promise) {
 			if cos := a.costume; cos != nil {
-				cos.draw(CostumeSpeak(a.lookAt), a.costumePos())
+				cos.draw(&a.anim, CostumeSpeak(a.lookAt), a.costumePos(), a.scale)
 			}
 			if dialog.IsCompleted() {
 				done.Complete()
@@ -265,6 +367,9 @@ func SpeakingTo(dialog Future) *Action {
 // Cancel cancels the action.
 func (a *Action) Cancel() {
 	a.prom.Break()
+	if a.cancel != nil {
+		a.cancel()
+	}
 }
 
 // Done returns a future that will be completed when the action is done.
@@ -306,12 +411,20 @@ func (a *App) ActorShow(actor *Actor, pos Position, lookAt Direction) error {
 		return errors.New("no active room to show actor")
 	}
 
-	actor.Load(a.res)
-	a.viewport.Room.PutActor(actor)
-	actor.Locate(a.viewport.Room, pos, lookAt)
+	a.actorShowIn(a.viewport.Room, actor, pos, lookAt)
 	return nil
 }
 
+// actorShowIn shows the actor in the given room, removing it from the room it was in before.
+func (a *App) actorShowIn(room *Room, actor *Actor, pos Position, lookAt Direction) {
+	if actor.Room != nil && actor.Room != room {
+		actor.Room.RemoveActor(actor)
+	}
+	actor.Load(a.res)
+	room.PutActor(actor)
+	actor.Locate(room, pos, lookAt)
+}
+
 // ActorHide hides the actor from its current room.
 func (a *App) ActorHide(actor *Actor) {
 	if actor.Room != nil {
@@ -319,7 +432,12 @@ func (a *App) ActorHide(actor *Actor) {
 	}
 }
 
-// ActorEnter makes the actor enter the room through the given entrance.
+// ActorEnter makes the actor enter the room through the given entrance. The actor is put in the
+// room the entrance is declared in, which might not be the active one.
 func (a *App) ActorEnter(actor *Actor, entrance *Object) error {
-	return a.ActorShow(actor, entrance.UsePos, entrance.UseDir.Inverse())
+	if entrance.Room == nil {
+		return a.ActorShow(actor, entrance.UsePos, entrance.UseDir.Inverse())
+	}
+	a.actorShowIn(entrance.Room, actor, entrance.UsePos, entrance.UseDir.Inverse())
+	return nil
 }
@@ -9,13 +9,11 @@ const (
 	DefaultAnimationDelay = 100 * time.Millisecond
 )
 
-// Animation represents a sequence of images that can be played.
+// Animation represents a sequence of images that can be played. Animations are definitions that
+// may be shared by several actors or objects, so they are played using an AnimationPlayer.
 type Animation struct {
 	frames []animationFrame
 	flip   bool
-
-	currentFrame int
-	lastFrame    time.Time
 }
 
 // NewAnimation creates a new animation.
@@ -29,11 +27,23 @@ func NewAnimation() *Animation {
 // given.
 func (a *Animation) AddFrames(delay time.Duration, row int, sequence ...int) *Animation {
 	for _, col := range sequence {
-		a.frames = append(a.frames, animationFrame{uint(col), uint(row), delay})
+		a.frames = append(a.frames, animationFrame{col: uint(col), row: uint(row), delay: delay})
 	}
 	return a
 }
 
+// AddEvent attaches an event to the frame-th frame of the animation, counting from zero. The event
+// is fired every time the frame is shown.
+func (a *Animation) AddEvent(frame int, ev AnimationEvent) *Animation {
+	a.frames[frame].events = append(a.frames[frame].events, ev)
+	return a
+}
+
+// Frames returns the number of frames of the animation.
+func (a *Animation) Frames() int {
+	return len(a.frames)
+}
+
 // Flip sets the flip flag for the animation.
 func (a *Animation) Flip(flip bool) *Animation {
 	a.flip = flip
@@ -47,14 +57,24 @@ func (a *Animation) Flip(flip bool) *Animation {
 //   - byte: the sprite column.
 //   - byte: the sprite row.
 //   - uint64: the delay.
+//   - byte: the number of events.
+//   - for each event, the string name, the string sound reference and the connect flag.
 func (a *Animation) BinaryEncode(w io.Writer) (n int, err error) {
 	n, err = BinaryEncode(w, a.flip, uint32(len(a.frames)))
 	for _, frame := range a.frames {
-		nn, err := BinaryEncode(w, byte(frame.col), byte(frame.row), uint64(frame.delay))
+		nn, err := BinaryEncode(w, byte(frame.col), byte(frame.row), uint64(frame.delay),
+			byte(len(frame.events)))
 		n += nn
 		if err != nil {
 			return n, err
 		}
+		for _, ev := range frame.events {
+			nn, err := BinaryEncode(w, ev.Name, ev.Sound, ev.Connect)
+			n += nn
+			if err != nil {
+				return n, err
+			}
+		}
 	}
 	return n, nil
 }
@@ -67,9 +87,9 @@ func (a *Animation) BinaryDecode(r io.Reader) error {
 	}
 	a.frames = make([]animationFrame, count)
 	for i := uint32(0); i < count; i++ {
-		var col, row byte
+		var col, row, events byte
 		var delay uint64
-		if err := BinaryDecode(r, &col, &row, &delay); err != nil {
+		if err := BinaryDecode(r, &col, &row, &delay, &events); err != nil {
 			return err
 		}
 		a.frames[i] = animationFrame{
@@ -77,32 +97,179 @@ func (a *Animation) BinaryDecode(r io.Reader) error {
 			row:   uint(row),
 			delay: time.Duration(delay),
 		}
+		for j := byte(0); j < events; j++ {
+			var ev AnimationEvent
+			if err := BinaryDecode(r, &ev.Name, &ev.Sound, &ev.Connect); err != nil {
+				return err
+			}
+			a.frames[i].events = append(a.frames[i].events, ev)
+		}
 	}
 	return nil
 }
 
-// Draw renders the animation in the viewport.
-func (a *Animation) Draw(sprites *SpriteSheet, pos Position) {
-	if a == nil {
+type animationFrame struct {
+	col, row uint
+	delay    time.Duration
+	events   []AnimationEvent
+}
+
+// AnimationEvent is an event attached to a frame of an animation. It is fired when the frame is
+// shown.
+type AnimationEvent struct {
+	Name    string      // The name of the event emitted to scripts, or empty if none.
+	Sound   ResourceRef // The sound to play, or null if none.
+	Connect bool        // Whether this is the frame where the action connects.
+}
+
+// AnimationPlayer is the playback state of an animation for an actor or object instance. The zero
+// value is a player with no animation, ready to be used.
+type AnimationPlayer struct {
+	anim         *Animation
+	currentFrame int
+	lastFrame    time.Time
+	pending      []AnimationEvent
+	tint         Color // The color frames are tinted with, or blank for no tint.
+}
+
+// Play sets the animation to be played. If it is not the animation being played, the player starts
+// from its first frame.
+func (p *AnimationPlayer) Play(anim *Animation) {
+	if p.anim == anim {
+		return
+	}
+	p.anim = anim
+	p.Rewind()
+}
+
+// Rewind moves the player back to the first frame of the animation.
+func (p *AnimationPlayer) Rewind() {
+	p.currentFrame = 0
+	p.lastFrame = time.Now()
+	p.enter()
+}
+
+// TakeEvents returns the events of the frames shown since the last call.
+func (p *AnimationPlayer) TakeEvents() []AnimationEvent {
+	events := p.pending
+	p.pending = nil
+	return events
+}
+
+// Tint sets the color the frames are tinted with. A blank color means no tint.
+func (p *AnimationPlayer) Tint(c Color) {
+	p.tint = c
+}
+
+// Stop stops playing the animation. The next animation played starts from its first frame, even
+// if it is the same.
+func (p *AnimationPlayer) Stop() {
+	p.anim = nil
+}
+
+// Draw renders the current frame of the animation in the viewport at the given scale, looping over
+// its frames.
+func (p *AnimationPlayer) Draw(sprites *SpriteSheet, pos Position, scale float32) {
+	if p.anim == nil || len(p.anim.frames) == 0 {
 		return
 	}
-	if a.frames[a.currentFrame].delay < time.Since(a.lastFrame) {
-		a.lastFrame = time.Now()
-		a.currentFrame++
-		if a.currentFrame >= len(a.frames) {
-			a.currentFrame = 0
+	if moved, _ := p.advance(); moved {
+		p.enter()
+	}
+	p.drawFrame(sprites, pos, scale)
+}
+
+// DrawOnce renders the current frame of the animation in the viewport at the given scale until its
+// last frame is over. It returns true once the animation is over, in which case nothing is drawn.
+func (p *AnimationPlayer) DrawOnce(sprites *SpriteSheet, pos Position, scale float32) bool {
+	if p.anim == nil || len(p.anim.frames) == 0 {
+		return true
+	}
+	moved, wrapped := p.advance()
+	if wrapped {
+		return true
+	}
+	if moved {
+		p.enter()
+	}
+	p.drawFrame(sprites, pos, scale)
+	return false
+}
+
+// advance moves to the next frame if the current one is over. It returns whether it moved, and
+// whether the animation wrapped around to its first frame.
+func (p *AnimationPlayer) advance() (moved, wrapped bool) {
+	if p.anim.frames[p.currentFrame].delay >= time.Since(p.lastFrame) {
+		return false, false
+	}
+	p.lastFrame = time.Now()
+	p.currentFrame++
+	if p.currentFrame >= len(p.anim.frames) {
+		p.currentFrame = 0
+		return true, true
+	}
+	return true, false
+}
+
+// enter queues the events of the current frame, which has just been shown.
+func (p *AnimationPlayer) enter() {
+	if p.anim == nil || len(p.anim.frames) == 0 {
+		return
+	}
+	p.pending = append(p.pending, p.anim.frames[p.currentFrame].events...)
+}
+
+// connects returns true if the action connects in the frames shown since the events were taken.
+func (p *AnimationPlayer) connects() bool {
+	for _, ev := range p.pending {
+		if ev.Connect {
+			return true
 		}
 	}
+	return false
+}
 
-	sprites.DrawSprite(
-		a.frames[a.currentFrame].col,
-		a.frames[a.currentFrame].row,
-		pos,
-		a.flip,
-	)
+func (p *AnimationPlayer) drawFrame(sprites *SpriteSheet, pos Position, scale float32) {
+	frame := p.anim.frames[p.currentFrame]
+	tint := White
+	if p.tint != Blank {
+		tint = p.tint
+	}
+	sprites.DrawSpriteTinted(frame.col, frame.row, pos, p.anim.flip, scale, tint)
 }
 
-type animationFrame struct {
-	col, row uint
-	delay    time.Duration
+// processAnimationEvents fires the events of the animation frames shown by the actors and objects
+// of the current room.
+func (a *App) processAnimationEvents() {
+	room := a.viewport.Room
+	if room == nil {
+		return
+	}
+	for _, actor := range room.actors {
+		for _, ev := range actor.anim.TakeEvents() {
+			a.fireAnimationEvent(ev, actor.ItemPosition())
+			if cb := actor.events[ev.Name]; ev.Name != "" && cb != nil {
+				cb.Invoke(nil)
+			}
+		}
+	}
+	for _, obj := range room.objects {
+		for _, ev := range obj.player.TakeEvents() {
+			a.fireAnimationEvent(ev, obj.ItemPosition())
+		}
+	}
+}
+
+// fireAnimationEvent plays the sound of the event, if any, panned by the position of the room where
+// the animation is shown.
+func (a *App) fireAnimationEvent(ev AnimationEvent, pos Position) {
+	if ev.Sound.IsNull() {
+		return
+	}
+	sound, ok := a.sounds[ev.Sound]
+	if !ok {
+		sound = NewSound(ev.Sound)
+		a.sounds[ev.Sound] = sound
+	}
+	a.PlaySoundAt(sound, pos)
 }
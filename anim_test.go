@@ -0,0 +1,64 @@
+package pctk
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnimationPlayer_Independent(t *testing.T) {
+	anim := NewAnimation().AddFrames(0, 0, 0, 1, 2)
+	var p1, p2 AnimationPlayer
+	p1.Play(anim)
+	p2.Play(anim)
+
+	_, wrapped := p1.advance()
+	assert.False(t, wrapped)
+	_, wrapped = p1.advance()
+	assert.False(t, wrapped)
+	assert.Equal(t, 2, p1.currentFrame)
+	assert.Equal(t, 0, p2.currentFrame)
+
+	_, wrapped = p1.advance()
+	assert.True(t, wrapped)
+	assert.Equal(t, 0, p1.currentFrame)
+}
+
+func TestAnimationPlayer_Play(t *testing.T) {
+	walk := NewAnimation().AddFrames(0, 0, 0, 1, 2)
+	idle := NewAnimation().AddFrames(0, 1, 0)
+	var p AnimationPlayer
+
+	p.Play(walk)
+	p.advance()
+	p.Play(walk)
+	assert.Equal(t, 1, p.currentFrame, "playing the same animation keeps the frame")
+
+	p.Play(idle)
+	p.Play(walk)
+	assert.Equal(t, 0, p.currentFrame, "changing the animation rewinds it")
+
+	p.advance()
+	p.Stop()
+	p.Play(walk)
+	assert.Equal(t, 0, p.currentFrame, "stopping the player rewinds the next animation")
+}
+
+func TestAnimationPlayer_TakeEvents(t *testing.T) {
+	step := AnimationEvent{Name: "step"}
+	hit := AnimationEvent{Connect: true}
+	anim := NewAnimation().AddFrames(0, 0, 0, 1, 2).AddEvent(0, step).AddEvent(2, hit)
+	var p AnimationPlayer
+
+	p.Play(anim)
+	assert.Equal(t, []AnimationEvent{step}, p.TakeEvents())
+	assert.Empty(t, p.TakeEvents())
+
+	p.advance()
+	p.enter()
+	assert.False(t, p.connects())
+	p.advance()
+	p.enter()
+	assert.True(t, p.connects())
+	assert.Equal(t, []AnimationEvent{hit}, p.TakeEvents())
+}
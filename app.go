@@ -10,9 +10,15 @@ type App struct {
 	res ResourceLoader
 
 	screenCaption string
+	screenIcon    ResourceRef
 	screenZoom    int32
 	debugMode     bool
 	debugEnabled  bool
+	language      string
+	startRoom     struct {
+		script ResourceRef
+		name   string
+	}
 
 	actors   []*Actor
 	defaults *ObjectDefaults
@@ -22,6 +28,8 @@ type App struct {
 	rooms    []*Room
 	scripts  map[ResourceRef]*Script
 	sound    *Sound
+	sounds   map[ResourceRef]*Sound
+	audible  *Room // The room whose sound emitters are playing.
 
 	cam      Camera
 	control  ControlPane
@@ -36,6 +44,7 @@ func New(resources ResourceLoader, opts ...AppOption) *App {
 	app := &App{
 		res:     resources,
 		scripts: make(map[ResourceRef]*Script),
+		sounds:  make(map[ResourceRef]*Sound),
 	}
 
 	opts = append(defaultAppOptions, opts...)
@@ -66,6 +75,11 @@ func (a *App) Run() {
 
 func (a *App) init() {
 	rl.InitWindow(ScreenWidth*a.screenZoom, ScreenHeight*a.screenZoom, a.screenCaption)
+	if a.screenIcon != ResourceRefNull {
+		icon := a.res.LoadImage(a.screenIcon)
+		rl.ImageFormat(icon.raw, rl.UncompressedR8g8b8a8)
+		rl.SetWindowIcon(*icon.raw)
+	}
 	rl.InitAudioDevice()
 	rl.SetTargetFPS(60)
 	rl.HideCursor()
@@ -87,6 +101,10 @@ func (a *App) processFrame() {
 	rl.ClearBackground(rl.Black)
 
 	a.viewport.ProcessFrame(a.frame)
+	a.processAnimationEvents()
+	a.processRoomSounds()
+	a.processRoomUpdate()
+	a.processZoneEvents()
 	a.control.ProcessFrame(a, a.frame)
 	a.frame.WithCamera(&a.cam, func(f *Frame) {
 		a.mouse.Draw(f)
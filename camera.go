@@ -1,6 +1,9 @@
 package pctk
 
 import (
+	"math/rand"
+	"time"
+
 	rl "github.com/gen2brain/raylib-go/raylib"
 )
 
@@ -22,6 +25,10 @@ func (c Camera) WithZoom(zoom float32) Camera {
 	return c
 }
 
+func (c Camera) Zoom() float32 {
+	return c.raw.Zoom
+}
+
 func (c Camera) ScreenToWorldPosition(pos Position) Position {
 	return positionFromRaylib(rl.GetScreenToWorld2D(pos.toRaylib(), c.raw))
 }
@@ -35,3 +42,49 @@ func (c Camera) Action(act func()) {
 	act()
 	rl.EndMode2D()
 }
+
+// cameraMove is a transition of the camera position and zoom eased over a period of time.
+type cameraMove struct {
+	fromPos, toPos   Positionf
+	fromZoom, toZoom float32
+	start            time.Time
+	duration         time.Duration
+	done             *Promise
+}
+
+// at returns the camera position and zoom at the given instant, and whether the move is finished.
+func (m *cameraMove) at(now time.Time) (Positionf, float32, bool) {
+	t := float32(1)
+	if m.duration > 0 {
+		t = min(1, float32(now.Sub(m.start))/float32(m.duration))
+	}
+	e := easeInOut(t)
+	pos := m.fromPos.Add(m.toPos.Sub(m.fromPos).Scale(e))
+	zoom := m.fromZoom + (m.toZoom-m.fromZoom)*e
+	return pos, zoom, t >= 1
+}
+
+// easeInOut eases a linear progress from 0 to 1 so it accelerates at the start and decelerates at
+// the end.
+func easeInOut(t float32) float32 {
+	return t * t * (3 - 2*t)
+}
+
+// cameraShake is a random displacement of the camera that fades out over a period of time.
+type cameraShake struct {
+	intensity float32
+	start     time.Time
+	duration  time.Duration
+	done      *Promise
+}
+
+// offset returns the displacement of the camera at the given instant, and whether the shake is
+// finished.
+func (s *cameraShake) offset(now time.Time) (Positionf, bool) {
+	elapsed := now.Sub(s.start)
+	if elapsed >= s.duration {
+		return Positionf{}, true
+	}
+	amount := s.intensity * (1 - float32(elapsed)/float32(s.duration))
+	return NewPosf((rand.Float32()*2-1)*amount, (rand.Float32()*2-1)*amount), false
+}
@@ -0,0 +1,67 @@
+package pctk
+
+import (
+	"testing"
+	"time"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCameraMove_at(t *testing.T) {
+	start := time.Now()
+	move := cameraMove{
+		fromPos:  NewPosf(0, 0),
+		toPos:    NewPosf(100, 50),
+		fromZoom: 1,
+		toZoom:   2,
+		start:    start,
+		duration: time.Second,
+	}
+
+	pos, zoom, finished := move.at(start)
+	assert.Equal(t, NewPosf(0, 0), pos)
+	assert.Equal(t, float32(1), zoom)
+	assert.False(t, finished)
+
+	pos, zoom, finished = move.at(start.Add(500 * time.Millisecond))
+	assert.Equal(t, NewPosf(50, 25), pos)
+	assert.Equal(t, float32(1.5), zoom)
+	assert.False(t, finished)
+
+	pos, _, _ = move.at(start.Add(100 * time.Millisecond))
+	assert.Less(t, pos.X, float32(10), "the camera must ease in")
+
+	pos, zoom, finished = move.at(start.Add(2 * time.Second))
+	assert.Equal(t, NewPosf(100, 50), pos)
+	assert.Equal(t, float32(2), zoom)
+	assert.True(t, finished)
+}
+
+func TestViewport_clampCamera(t *testing.T) {
+	room := NewRoom()
+	room.background = &Image{raw: &rl.Image{Width: 640, Height: 288}}
+	v := Viewport{Room: room}
+
+	assert.Equal(t, NewPosf(0, 0), v.clampCamera(NewPosf(-10, -10), 1))
+	assert.Equal(t, NewPosf(320, 144), v.clampCamera(NewPosf(1000, 1000), 1))
+	assert.Equal(t, NewPosf(480, 216), v.clampCamera(NewPosf(1000, 1000), 2))
+	assert.Equal(t, NewPosf(0, 0), v.clampCamera(NewPosf(1000, 1000), 0.5))
+}
+
+func TestViewport_followTarget(t *testing.T) {
+	room := NewRoom()
+	room.background = &Image{raw: &rl.Image{Width: 640, Height: 288}}
+	actor := NewActor("guybrush")
+	v := Viewport{Room: room, camzoom: 1, follow: actor, deadzone: NewSize(80, 40)}
+
+	// The camera is centered at (160, 72), so the dead zone spans from (120, 52) to (200, 92).
+	actor.pos = NewPosf(190, 60)
+	assert.Equal(t, NewPosf(0, 0), v.followTarget())
+
+	actor.pos = NewPosf(300, 100)
+	assert.Equal(t, NewPosf(100, 8), v.followTarget())
+
+	v.deadzone = Size{}
+	assert.Equal(t, NewPosf(140, 28), v.followTarget())
+}
@@ -0,0 +1,51 @@
+package lint
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/apoloval/pctk"
+	"github.com/spf13/cobra"
+)
+
+var Command = &cobra.Command{
+	Use:   "lint [[name=]src|package.idx]...",
+	Short: "check game scripts for broken resource references and other content errors",
+	Long: `Check the Lua scripts of the given source directories for content errors.
+
+Each source directory is taken as a resource package named after the directory, unless a different
+name is given as name=src. Arguments ending in .idx are taken as packaged resources, which can be
+referenced but whose scripts are not checked.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		linter := NewLinter()
+		for _, arg := range args {
+			var err error
+			if strings.HasSuffix(arg, ".idx") {
+				err = linter.AddPackage(arg)
+			} else {
+				name, dir, found := strings.Cut(arg, "=")
+				if !found {
+					dir, name = arg, filepath.Base(filepath.Clean(arg))
+				}
+				err = linter.AddSourceDir(pctk.ResourcePackage(name), dir)
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		issues := linter.Run()
+		for _, issue := range issues {
+			fmt.Fprintln(os.Stderr, issue)
+		}
+		if len(issues) > 0 {
+			cmd.SilenceUsage = true
+			cmd.SilenceErrors = true
+			return fmt.Errorf("%d issues found", len(issues))
+		}
+		return nil
+	},
+}
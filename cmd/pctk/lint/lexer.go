@@ -0,0 +1,178 @@
+package lint
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TokenKind is the kind of a Lua token.
+type TokenKind int
+
+const (
+	// TokenName is an identifier or keyword.
+	TokenName TokenKind = iota
+
+	// TokenString is a string literal. Its value is the unquoted string.
+	TokenString
+
+	// TokenNumber is a numeric literal.
+	TokenNumber
+
+	// TokenSymbol is an operator or punctuation symbol.
+	TokenSymbol
+)
+
+// Token is a lexical token of a Lua source.
+type Token struct {
+	Kind  TokenKind
+	Value string
+	Line  int
+}
+
+// Is returns true if the token is of the given kind and value.
+func (t Token) Is(kind TokenKind, value string) bool {
+	return t.Kind == kind && t.Value == value
+}
+
+// Tokenize splits a Lua source into tokens, discarding whitespaces and comments. This is not a
+// complete Lua lexer: it is just accurate enough to find the constructs checked by the linter.
+func Tokenize(src string) ([]Token, error) {
+	var tokens []Token
+	line := 1
+	i := 0
+	for i < len(src) {
+		c := src[i]
+		switch {
+		case c == '\n':
+			line++
+			i++
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+		case strings.HasPrefix(src[i:], "--"):
+			i += 2
+			if level, ok := longBracketLevel(src[i:]); ok {
+				end, lines, err := skipLongBracket(src, i, level)
+				if err != nil {
+					return nil, fmt.Errorf("line %d: unfinished long comment", line)
+				}
+				line += lines
+				i = end
+				continue
+			}
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case isNameStart(c):
+			start := i
+			for i < len(src) && isNameChar(src[i]) {
+				i++
+			}
+			tokens = append(tokens, Token{TokenName, src[start:i], line})
+		case isDigit(c) || (c == '.' && i+1 < len(src) && isDigit(src[i+1])):
+			start := i
+			for i < len(src) && (isNameChar(src[i]) || src[i] == '.' ||
+				((src[i] == '-' || src[i] == '+') && strings.ContainsRune("eEpP", rune(src[i-1])))) {
+				i++
+			}
+			tokens = append(tokens, Token{TokenNumber, src[start:i], line})
+		case c == '"' || c == '\'':
+			start := line
+			var val strings.Builder
+			i++
+			for {
+				if i >= len(src) || src[i] == '\n' {
+					return nil, fmt.Errorf("line %d: unfinished string", start)
+				}
+				if src[i] == c {
+					i++
+					break
+				}
+				if src[i] == '\\' && i+1 < len(src) {
+					i++
+					switch src[i] {
+					case 'n':
+						val.WriteByte('\n')
+					case 't':
+						val.WriteByte('\t')
+					case '\n':
+						line++
+						val.WriteByte('\n')
+					default:
+						val.WriteByte(src[i])
+					}
+					i++
+					continue
+				}
+				val.WriteByte(src[i])
+				i++
+			}
+			tokens = append(tokens, Token{TokenString, val.String(), start})
+		case c == '[':
+			if level, ok := longBracketLevel(src[i:]); ok {
+				end, lines, err := skipLongBracket(src, i, level)
+				if err != nil {
+					return nil, fmt.Errorf("line %d: unfinished long string", line)
+				}
+				val := src[i+level+2 : end-level-2]
+				val = strings.TrimPrefix(val, "\n")
+				tokens = append(tokens, Token{TokenString, val, line})
+				line += lines
+				i = end
+				continue
+			}
+			tokens = append(tokens, Token{TokenSymbol, "[", line})
+			i++
+		default:
+			sym := string(c)
+			for _, op := range []string{"...", "..", "==", "~=", "<=", ">=", "::"} {
+				if strings.HasPrefix(src[i:], op) {
+					sym = op
+					break
+				}
+			}
+			tokens = append(tokens, Token{TokenSymbol, sym, line})
+			i += len(sym)
+		}
+	}
+	return tokens, nil
+}
+
+// longBracketLevel returns the level of the long bracket that opens at the beginning of s, if any.
+func longBracketLevel(s string) (int, bool) {
+	if len(s) == 0 || s[0] != '[' {
+		return 0, false
+	}
+	level := 1
+	for level < len(s) && s[level] == '=' {
+		level++
+	}
+	if level < len(s) && s[level] == '[' {
+		return level - 1, true
+	}
+	return 0, false
+}
+
+// skipLongBracket returns the offset after the long bracket of the given level that opens at
+// offset start, and the number of lines it spans.
+func skipLongBracket(src string, start, level int) (int, int, error) {
+	closing := "]" + strings.Repeat("=", level) + "]"
+	body := start + level + 2
+	end := strings.Index(src[body:], closing)
+	if end < 0 {
+		return 0, 0, fmt.Errorf("unfinished long bracket")
+	}
+	end += body + len(closing)
+	return end, strings.Count(src[start:end], "\n"), nil
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isNameChar(c byte) bool {
+	return isNameStart(c) || isDigit(c)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
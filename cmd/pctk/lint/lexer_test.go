@@ -0,0 +1,132 @@
+package lint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want []Token
+	}{
+		{
+			name: "names and symbols",
+			src:  "local door = object {",
+			want: []Token{
+				{TokenName, "local", 1},
+				{TokenName, "door", 1},
+				{TokenSymbol, "=", 1},
+				{TokenName, "object", 1},
+				{TokenSymbol, "{", 1},
+			},
+		},
+		{
+			name: "multi-character operators",
+			src:  "a ~= b .. c ... ::label:: <= >= ==",
+			want: []Token{
+				{TokenName, "a", 1},
+				{TokenSymbol, "~=", 1},
+				{TokenName, "b", 1},
+				{TokenSymbol, "..", 1},
+				{TokenName, "c", 1},
+				{TokenSymbol, "...", 1},
+				{TokenSymbol, "::", 1},
+				{TokenName, "label", 1},
+				{TokenSymbol, "::", 1},
+				{TokenSymbol, "<=", 1},
+				{TokenSymbol, ">=", 1},
+				{TokenSymbol, "==", 1},
+			},
+		},
+		{
+			name: "numbers",
+			src:  "42 3.14 .5 0xFF 1e-3 2E+4",
+			want: []Token{
+				{TokenNumber, "42", 1},
+				{TokenNumber, "3.14", 1},
+				{TokenNumber, ".5", 1},
+				{TokenNumber, "0xFF", 1},
+				{TokenNumber, "1e-3", 1},
+				{TokenNumber, "2E+4", 1},
+			},
+		},
+		{
+			name: "quoted strings",
+			src:  `"double" 'single' "say \"hi\"" 'a\tb\n'`,
+			want: []Token{
+				{TokenString, "double", 1},
+				{TokenString, "single", 1},
+				{TokenString, `say "hi"`, 1},
+				{TokenString, "a\tb\n", 1},
+			},
+		},
+		{
+			name: "long strings",
+			src:  "[[\nfirst\nsecond]] [==[with ]] inside]==] x",
+			want: []Token{
+				{TokenString, "first\nsecond", 1},
+				{TokenString, "with ]] inside", 3},
+				{TokenName, "x", 3},
+			},
+		},
+		{
+			name: "index is not a long string",
+			src:  "t[1]",
+			want: []Token{
+				{TokenName, "t", 1},
+				{TokenSymbol, "[", 1},
+				{TokenNumber, "1", 1},
+				{TokenSymbol, "]", 1},
+			},
+		},
+		{
+			name: "comments",
+			src:  "a -- line comment\n--[[ long\ncomment ]] b\n--[=[ ]] ]=] c",
+			want: []Token{
+				{TokenName, "a", 1},
+				{TokenName, "b", 3},
+				{TokenName, "c", 4},
+			},
+		},
+		{
+			name: "line numbers",
+			src:  "a\r\n\n  b\n\"multi\\\nline\" c",
+			want: []Token{
+				{TokenName, "a", 1},
+				{TokenName, "b", 3},
+				{TokenString, "multi\nline", 4},
+				{TokenName, "c", 5},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, err := Tokenize(tt.src)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, tokens)
+		})
+	}
+}
+
+func TestTokenize_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{name: "unfinished string", src: "a\n\"hello", want: "line 2: unfinished string"},
+		{name: "newline in string", src: "'hello\nworld'", want: "line 1: unfinished string"},
+		{name: "unfinished long string", src: "x = [==[ hello ]]", want: "line 1: unfinished long string"},
+		{name: "unfinished long comment", src: "\n--[[ hello", want: "line 2: unfinished long comment"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Tokenize(tt.src)
+			assert.EqualError(t, err, tt.want)
+		})
+	}
+}
@@ -0,0 +1,425 @@
+package lint
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/apoloval/pctk"
+	"github.com/apoloval/pctk/cmd/pctk/pack"
+	"gopkg.in/yaml.v3"
+)
+
+// Issue is a problem found by the linter.
+type Issue struct {
+	File string
+	Line int
+	Msg  string
+}
+
+// String returns the issue in the usual file:line: message format.
+func (i Issue) String() string {
+	return fmt.Sprintf("%s:%d: %s", i.File, i.Line, i.Msg)
+}
+
+// Linter checks the Lua scripts of a game against the resources it references.
+type Linter struct {
+	resources map[pctk.ResourcePackage]map[pctk.ResourceID]pack.ResourceType
+	objects   []string // The objects declared in room manifests.
+	walkboxes []string // The walkboxes declared in room manifests.
+	scripts   []luaScript
+	issues    []Issue
+}
+
+type luaScript struct {
+	path   string
+	tokens []Token
+}
+
+// NewLinter creates a new linter with no resources.
+func NewLinter() *Linter {
+	return &Linter{
+		resources: make(map[pctk.ResourcePackage]map[pctk.ResourceID]pack.ResourceType),
+	}
+}
+
+// AddSourceDir adds the resources of a source directory as they would be packed by `pctk pack` into
+// the given package. The Lua scripts found in the directory will be linted.
+func (l *Linter) AddSourceDir(pkg pctk.ResourcePackage, dir string) error {
+	resources := l.packageResources(pkg)
+	return filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		id := pctk.ResourceID(filepath.ToSlash(strings.TrimSuffix(rel, filepath.Ext(rel))))
+
+		switch filepath.Ext(path) {
+		case ".yml", ".yaml":
+			typ, objects, walkboxes, err := loadManifestType(path)
+			if err != nil {
+				return fmt.Errorf("error reading manifest %s: %w", path, err)
+			}
+			resources[id] = typ
+			l.objects = append(l.objects, objects...)
+			l.walkboxes = append(l.walkboxes, walkboxes...)
+		case ".tmx", ".tmj":
+			resources[id] = pack.ManifestTypeRoomGeometry
+		case ".lua":
+			code, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			resources[id] = pack.ManifestTypeScript
+			tokens, err := Tokenize(string(code))
+			if err != nil {
+				return fmt.Errorf("error parsing script %s: %w", path, err)
+			}
+			l.scripts = append(l.scripts, luaScript{path: path, tokens: tokens})
+		}
+		return nil
+	})
+}
+
+// AddPackage adds the resources of a packaged file. The path may be the .idx file or the package
+// path without extension.
+func (l *Linter) AddPackage(path string) error {
+	path = strings.TrimSuffix(path, ".idx")
+	pkg := pctk.ResourcePackage(filepath.Base(path))
+	loader := pctk.NewResourceFileLoader(filepath.Dir(path))
+	entries, err := loader.ListResources(pkg)
+	if err != nil {
+		return fmt.Errorf("error reading package %s: %w", path, err)
+	}
+	resources := l.packageResources(pkg)
+	for id, typ := range entries {
+		resources[id] = pack.ResourceType(typ)
+	}
+	return nil
+}
+
+// Run checks the scripts and returns the issues found, sorted by file and line.
+func (l *Linter) Run() []Issue {
+	l.issues = nil
+	objects := make(map[string]bool)
+	for _, name := range l.objects {
+		objects[name] = true
+	}
+	zones := make(map[string]bool)
+	for _, name := range l.walkboxes {
+		zones[name] = true
+	}
+	for _, s := range l.scripts {
+		for _, name := range declaredEntities(s.tokens, "object") {
+			objects[name] = true
+		}
+		for _, name := range declaredEntities(s.tokens, "walkbox") {
+			zones[name] = true
+		}
+		for _, name := range declaredEntities(s.tokens, "trigger") {
+			zones[name] = true
+		}
+	}
+	for _, s := range l.scripts {
+		l.checkRefs(s)
+		l.checkWalkBoxes(s)
+		l.checkCallbacks(s, objects, zones)
+	}
+	sort.SliceStable(l.issues, func(i, j int) bool {
+		if l.issues[i].File != l.issues[j].File {
+			return l.issues[i].File < l.issues[j].File
+		}
+		return l.issues[i].Line < l.issues[j].Line
+	})
+	return l.issues
+}
+
+func (l *Linter) packageResources(pkg pctk.ResourcePackage) map[pctk.ResourceID]pack.ResourceType {
+	resources, ok := l.resources[pkg]
+	if !ok {
+		resources = make(map[pctk.ResourceID]pack.ResourceType)
+		l.resources[pkg] = resources
+	}
+	return resources
+}
+
+func (l *Linter) report(s luaScript, line int, format string, args ...any) {
+	l.issues = append(l.issues, Issue{File: s.path, Line: line, Msg: fmt.Sprintf(format, args...)})
+}
+
+// refFunctions are the functions that receive a resource reference, and the type of resource they
+// expect. An empty type means any type.
+var refFunctions = map[string]pack.ResourceType{
+	"ref":    "",
+	"import": pack.ManifestTypeScript,
+	"music":  pack.ManifestTypeMusic,
+	"sound":  pack.ManifestTypeSound,
+}
+
+// refFields are the constructor fields that receive a resource reference, and the type of resource
+// they expect.
+var refFields = map[string]pack.ResourceType{
+	"background": pack.ManifestTypeImage,
+	"costume":    pack.ManifestTypeCostume,
+	"geometry":   pack.ManifestTypeRoomGeometry,
+	"sound":      pack.ManifestTypeSound,
+	"sprites":    pack.ManifestTypeSpriteSheet,
+}
+
+func (l *Linter) checkRefs(s luaScript) {
+	toks := s.tokens
+	for i, tok := range toks {
+		expected, ok := refFunctions[tok.Value]
+		if tok.Kind != TokenName || !ok {
+			continue
+		}
+		if i > 0 && (toks[i-1].Is(TokenSymbol, ".") || toks[i-1].Is(TokenSymbol, ":") ||
+			toks[i-1].Is(TokenName, "function")) {
+			continue
+		}
+		arg, ok := literalArg(toks, i+1)
+		if !ok {
+			continue
+		}
+		if expected == "" && i >= 2 && toks[i-1].Is(TokenSymbol, "=") && toks[i-2].Kind == TokenName {
+			expected = refFields[toks[i-2].Value]
+		}
+		if expected == "" && i >= 2 && toks[i-1].Is(TokenSymbol, "(") && toks[i-2].Is(TokenName, "room") {
+			expected = pack.ManifestTypeRoom
+		}
+
+		ref, err := pctk.ParseResourceRef(arg.Value)
+		if err != nil {
+			l.report(s, arg.Line, "invalid resource reference %q", arg.Value)
+			continue
+		}
+		resources, ok := l.resources[ref.Package()]
+		if !ok {
+			l.report(s, arg.Line, "unknown resource package %q in %s", ref.Package(), ref)
+			continue
+		}
+		typ, ok := resources[ref.ID()]
+		if !ok {
+			l.report(s, arg.Line, "resource %s not found", ref)
+			continue
+		}
+		if expected != "" && typ != expected {
+			l.report(s, arg.Line, "resource %s is a %s, expected %s", ref, typ, expected)
+		}
+	}
+}
+
+func (l *Linter) checkWalkBoxes(s luaScript) {
+	toks := s.tokens
+	for i, tok := range toks {
+		if !tok.Is(TokenName, "walkbox") || i+1 >= len(toks) || !toks[i+1].Is(TokenSymbol, "{") {
+			continue
+		}
+		name := "walkbox"
+		if i >= 2 && toks[i-1].Is(TokenSymbol, "=") && toks[i-2].Kind == TokenName {
+			name = fmt.Sprintf("walkbox %s", toks[i-2].Value)
+		}
+		end := matchingBrace(toks, i+1)
+		for j := i + 2; j+2 < end; j++ {
+			if !toks[j].Is(TokenName, "vertices") || !toks[j+1].Is(TokenSymbol, "=") ||
+				!toks[j+2].Is(TokenSymbol, "{") {
+				continue
+			}
+			vertices, literal := literalVertices(toks, j+2)
+			if len(vertices) < 3 {
+				l.report(s, tok.Line, "%s must have at least 3 vertices, got %d", name, len(vertices))
+			} else if literal && !pctk.IsConvexPolygon(vertices) {
+				l.report(s, tok.Line, "%s must be a convex polygon: %v", name, vertices)
+			}
+			break
+		}
+	}
+}
+
+func (l *Linter) checkCallbacks(s luaScript, objects, zones map[string]bool) {
+	verbs := make(map[string]bool)
+	for _, v := range pctk.Verbs {
+		verbs[v.Action()] = true
+	}
+
+	toks := s.tokens
+	for i, tok := range toks {
+		if !tok.Is(TokenName, "function") {
+			continue
+		}
+		// Match `function a.b...c:method(`
+		var path []string
+		j := i + 1
+		for j < len(toks) && toks[j].Kind == TokenName {
+			path = append(path, toks[j].Value)
+			if j+1 < len(toks) && toks[j+1].Is(TokenSymbol, ".") {
+				j += 2
+				continue
+			}
+			break
+		}
+		if len(path) < 2 || j+2 >= len(toks) || !toks[j+1].Is(TokenSymbol, ":") ||
+			toks[j+2].Kind != TokenName {
+			continue
+		}
+		object, method := path[len(path)-1], toks[j+2].Value
+		if objects[object] && !verbs[method] {
+			l.report(s, toks[j+2].Line, "callback %q of object %q does not match any verb",
+				method, object)
+		}
+		if zones[object] && method != "enter" && method != "leave" {
+			l.report(s, toks[j+2].Line, "callback %q of %q must be either enter or leave",
+				method, object)
+		}
+	}
+}
+
+// literalArg returns the string literal passed as only argument of a function call whose arguments
+// begin at the i-th token, either as `f("lit")` or `f "lit"`.
+func literalArg(toks []Token, i int) (Token, bool) {
+	if i < len(toks) && toks[i].Kind == TokenString {
+		return toks[i], true
+	}
+	if i+2 < len(toks) && toks[i].Is(TokenSymbol, "(") && toks[i+1].Kind == TokenString &&
+		toks[i+2].Is(TokenSymbol, ")") {
+		return toks[i+1], true
+	}
+	return Token{}, false
+}
+
+// matchingBrace returns the index of the brace that closes the one at the i-th token.
+func matchingBrace(toks []Token, i int) int {
+	depth := 0
+	for j := i; j < len(toks); j++ {
+		switch {
+		case toks[j].Is(TokenSymbol, "{"):
+			depth++
+		case toks[j].Is(TokenSymbol, "}"):
+			depth--
+			if depth == 0 {
+				return j
+			}
+		}
+	}
+	return len(toks)
+}
+
+// literalVertices returns the vertices of the table that opens at the i-th token. It returns false
+// as second value if some vertex is not a literal `pos {x=..., y=...}` expression.
+func literalVertices(toks []Token, i int) ([]pctk.Position, bool) {
+	var vertices []pctk.Position
+	literal := true
+	end := matchingBrace(toks, i)
+	j := i + 1
+	for j < end {
+		if toks[j].Is(TokenSymbol, ",") || toks[j].Is(TokenSymbol, ";") {
+			j++
+			continue
+		}
+
+		// Skip to the end of the item.
+		next := j
+		for next < end && !toks[next].Is(TokenSymbol, ",") && !toks[next].Is(TokenSymbol, ";") {
+			if toks[next].Is(TokenSymbol, "{") {
+				next = matchingBrace(toks, next)
+			}
+			next++
+		}
+
+		pos, ok := literalPos(toks[j:next])
+		if !ok {
+			literal = false
+		}
+		vertices = append(vertices, pos)
+		j = next
+	}
+	return vertices, literal
+}
+
+// literalPos parses a `pos {x=..., y=...}` expression.
+func literalPos(toks []Token) (pctk.Position, bool) {
+	var pos pctk.Position
+	if len(toks) < 3 || !toks[0].Is(TokenName, "pos") || !toks[1].Is(TokenSymbol, "{") {
+		return pos, false
+	}
+	found := 0
+	for k := 2; k+2 < len(toks); k++ {
+		if toks[k].Kind != TokenName || !toks[k+1].Is(TokenSymbol, "=") {
+			continue
+		}
+		val := toks[k+2]
+		neg := false
+		if val.Is(TokenSymbol, "-") && k+3 < len(toks) {
+			neg, val = true, toks[k+3]
+		}
+		if val.Kind != TokenNumber {
+			return pos, false
+		}
+		n, err := strconv.Atoi(val.Value)
+		if err != nil {
+			return pos, false
+		}
+		if neg {
+			n = -n
+		}
+		switch toks[k].Value {
+		case "x":
+			pos.X = n
+			found++
+		case "y":
+			pos.Y = n
+			found++
+		}
+	}
+	return pos, found == 2
+}
+
+// declaredEntities returns the names of the entities declared as `name = ctor {...}`.
+func declaredEntities(toks []Token, ctor string) []string {
+	var names []string
+	for i := 2; i+1 < len(toks); i++ {
+		if toks[i].Is(TokenName, ctor) && toks[i+1].Is(TokenSymbol, "{") &&
+			toks[i-1].Is(TokenSymbol, "=") && toks[i-2].Kind == TokenName {
+			names = append(names, toks[i-2].Value)
+		}
+	}
+	return names
+}
+
+// loadManifestType returns the type of the resource described by a manifest, along with the IDs of
+// the objects and walkboxes it declares if it is a room.
+func loadManifestType(path string) (typ pack.ResourceType, objects, walkboxes []string, err error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	var header struct {
+		Type pack.ResourceType
+		Data struct {
+			Objects []struct {
+				ID string
+			}
+			WalkBoxes []struct {
+				ID string
+			}
+		}
+	}
+	if err := yaml.Unmarshal(data, &header); err != nil {
+		return "", nil, nil, err
+	}
+	if header.Type == pack.ManifestTypeRoom {
+		for _, obj := range header.Data.Objects {
+			objects = append(objects, obj.ID)
+		}
+		for _, wb := range header.Data.WalkBoxes {
+			walkboxes = append(walkboxes, wb.ID)
+		}
+	}
+	return header.Type, objects, walkboxes, nil
+}
@@ -0,0 +1,211 @@
+package lint
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// lintFixture are the resources of the source directory linted by the tests, besides the script
+// under test.
+var lintFixture = map[string]string{
+	"backgrounds/hall.yml": "type: image\n",
+	"costumes/ego.yml":     "type: costume\n",
+	"music/theme.yml":      "type: music\n",
+	"sounds/door.yml":      "type: sound\n",
+	"rooms/hall.yml": `type: room
+data:
+  objects:
+    - id: door
+  walkboxes:
+    - id: floor
+`,
+	"scripts/common.lua": "-- Nothing to see here.\n",
+}
+
+// lintScript lints the given script along with the fixture resources, and returns the issues found
+// in the script as line: message.
+func lintScript(t *testing.T, code string) []string {
+	dir := t.TempDir()
+	files := map[string]string{"scripts/main.lua": code}
+	for path, content := range lintFixture {
+		files[path] = content
+	}
+	for path, content := range files {
+		path = filepath.Join(dir, path)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+
+	l := NewLinter()
+	require.NoError(t, l.AddSourceDir("game", dir))
+	var issues []string
+	for _, issue := range l.Run() {
+		issue.File = filepath.Base(issue.File)
+		issues = append(issues, issue.String())
+	}
+	return issues
+}
+
+func TestLinter_checkRefs(t *testing.T) {
+	tests := []struct {
+		name string
+		code string
+		want []string
+	}{
+		{
+			name: "valid references",
+			code: `import "game:scripts/common"
+music("game:music/theme"):play()
+local hall = room { background = ref "game:backgrounds/hall" }
+local ego = actor { costume = ref("game:costumes/ego") }
+local r = room(ref "game:rooms/hall")
+sound "game:sounds/door"`,
+		},
+		{
+			name: "invalid reference",
+			code: `import "common"`,
+			want: []string{`main.lua:1: invalid resource reference "common"`},
+		},
+		{
+			name: "unknown package",
+			code: `import "other:scripts/common"`,
+			want: []string{`main.lua:1: unknown resource package "other" in other:scripts/common`},
+		},
+		{
+			name: "missing resource",
+			code: "\nmusic \"game:music/credits\"",
+			want: []string{"main.lua:2: resource game:music/credits not found"},
+		},
+		{
+			name: "wrong type in function",
+			code: `sound "game:music/theme"`,
+			want: []string{"main.lua:1: resource game:music/theme is a music, expected sound"},
+		},
+		{
+			name: "wrong type in field",
+			code: `local ego = actor { costume = ref "game:backgrounds/hall" }`,
+			want: []string{"main.lua:1: resource game:backgrounds/hall is a image, expected costume"},
+		},
+		{
+			name: "wrong type in room",
+			code: `local r = room(ref "game:costumes/ego")`,
+			want: []string{"main.lua:1: resource game:costumes/ego is a costume, expected room"},
+		},
+		{
+			name: "methods and non-literal arguments are ignored",
+			code: `obj:sound("nothing")
+function ref(x) end
+import(name)`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, lintScript(t, tt.code))
+		})
+	}
+}
+
+func TestLinter_checkWalkBoxes(t *testing.T) {
+	tests := []struct {
+		name string
+		code string
+		want []string
+	}{
+		{
+			name: "convex walkbox",
+			code: `local floor = walkbox {
+    vertices = { pos { x = 0, y = 100 }, pos { x = 320, y = 100 }, pos { x = 320, y = 140 } },
+}`,
+		},
+		{
+			name: "too few vertices",
+			code: `local floor = walkbox { vertices = { pos { x = 0, y = 100 }, pos { x = 320, y = 100 } } }`,
+			want: []string{"main.lua:1: walkbox floor must have at least 3 vertices, got 2"},
+		},
+		{
+			name: "concave walkbox",
+			code: `
+local floor = walkbox {
+    vertices = {
+        pos { x = 0, y = 0 }, pos { x = 100, y = 0 }, pos { x = 50, y = 20 },
+        pos { x = 100, y = 100 }, pos { x = 0, y = 100 },
+    },
+}`,
+			want: []string{"main.lua:2: walkbox floor must be a convex polygon: " +
+				"[(X:0, Y:0) (X:100, Y:0) (X:50, Y:20) (X:100, Y:100) (X:0, Y:100)]"},
+		},
+		{
+			name: "anonymous walkbox",
+			code: `hall:walkboxes { walkbox { vertices = { pos { x = 0, y = 0 } } } }`,
+			want: []string{"main.lua:1: walkbox must have at least 3 vertices, got 1"},
+		},
+		{
+			name: "non-literal vertices are not checked for convexity",
+			code: `local floor = walkbox {
+    vertices = { pos { x = 0, y = 0 }, pos { x = 100, y = 0 }, pos { x = 50, y = 20 }, corner, pos { x = 0, y = 100 } },
+}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, lintScript(t, tt.code))
+		})
+	}
+}
+
+func TestLinter_checkCallbacks(t *testing.T) {
+	tests := []struct {
+		name string
+		code string
+		want []string
+	}{
+		{
+			name: "verb callbacks of room objects",
+			code: `function hall.door:open() end
+function hall.door:lookat() end`,
+		},
+		{
+			name: "unknown verb of room object",
+			code: `function hall.door:knock() end`,
+			want: []string{`main.lua:1: callback "knock" of object "door" does not match any verb`},
+		},
+		{
+			name: "unknown verb of script object",
+			code: `local lamp = object {}
+function hall.lamp:smash() end`,
+			want: []string{`main.lua:2: callback "smash" of object "lamp" does not match any verb`},
+		},
+		{
+			name: "zone callbacks",
+			code: `local trap = trigger {}
+function hall.floor:enter() end
+function hall.trap:leave() end`,
+		},
+		{
+			name: "wrong zone callbacks",
+			code: `local puddle = walkbox { vertices = {} }
+function hall.floor:step() end
+function hall.puddle:splash() end`,
+			want: []string{
+				"main.lua:1: walkbox puddle must have at least 3 vertices, got 0",
+				`main.lua:2: callback "step" of "floor" must be either enter or leave`,
+				`main.lua:3: callback "splash" of "puddle" must be either enter or leave`,
+			},
+		},
+		{
+			name: "other functions are ignored",
+			code: `function hall:enter() end
+function ego:knock() end
+function helper() end`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, lintScript(t, tt.code))
+		})
+	}
+}
@@ -4,7 +4,10 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/apoloval/pctk/cmd/pctk/lint"
 	"github.com/apoloval/pctk/cmd/pctk/pack"
+	"github.com/apoloval/pctk/cmd/pctk/run"
+	"github.com/apoloval/pctk/cmd/pctk/scaffold"
 	"github.com/spf13/cobra"
 )
 
@@ -24,5 +27,8 @@ func main() {
 }
 
 func init() {
+	cmd.AddCommand(lint.Command)
 	cmd.AddCommand(pack.Command)
+	cmd.AddCommand(run.Command)
+	cmd.AddCommand(scaffold.Command)
 }
@@ -0,0 +1,299 @@
+package pack
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/apoloval/pctk"
+)
+
+// AsepriteCommand is the Aseprite executable used to export .aseprite files. It can be overridden
+// with the ASEPRITE environment variable.
+var AsepriteCommand = "aseprite"
+
+// AsepriteSheet is a sprite sheet exported by Aseprite in JSON format, either as array or hash.
+type AsepriteSheet struct {
+	Frames AsepriteFrames `json:"frames"`
+	Meta   struct {
+		Image     string        `json:"image"`
+		FrameTags []AsepriteTag `json:"frameTags"`
+	} `json:"meta"`
+
+	dir    string
+	loaded *pctk.SpriteSheet
+}
+
+// AsepriteFrame is a frame of an Aseprite sprite sheet.
+type AsepriteFrame struct {
+	Frame            asepriteRect `json:"frame"`
+	SpriteSourceSize asepriteRect `json:"spriteSourceSize"`
+	SourceSize       struct {
+		W int `json:"w"`
+		H int `json:"h"`
+	} `json:"sourceSize"`
+	Duration int `json:"duration"`
+}
+
+// AsepriteFrames are the frames of an Aseprite sprite sheet, in the order they were exported.
+type AsepriteFrames []AsepriteFrame
+
+// AsepriteTag is an Aseprite tag, a named range of frames.
+type AsepriteTag struct {
+	Name      string `json:"name"`
+	From      int    `json:"from"`
+	To        int    `json:"to"`
+	Direction string `json:"direction"`
+}
+
+type asepriteRect struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+	W int `json:"w"`
+	H int `json:"h"`
+}
+
+// UnmarshalJSON decodes the frames from an array or from a hash, preserving the order of the hash
+// entries.
+func (f *AsepriteFrames) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '[' {
+		return json.Unmarshal(data, (*[]AsepriteFrame)(f))
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if _, err := dec.Token(); err != nil {
+		return err
+	}
+	*f = nil
+	for dec.More() {
+		if _, err := dec.Token(); err != nil {
+			return err
+		}
+		var frame AsepriteFrame
+		if err := dec.Decode(&frame); err != nil {
+			return err
+		}
+		*f = append(*f, frame)
+	}
+	_, err := dec.Token()
+	return err
+}
+
+// LoadAseprite loads an Aseprite sprite sheet. The path is either a JSON file exported by Aseprite
+// or an .aseprite file, which is exported using the Aseprite command.
+func LoadAseprite(path string) (*AsepriteSheet, error) {
+	if isAsepriteFile(path) {
+		dir, err := os.MkdirTemp("", "pctk-aseprite")
+		if err != nil {
+			return nil, err
+		}
+		defer os.RemoveAll(dir)
+		if path, err = exportAseprite(path, dir); err != nil {
+			return nil, err
+		}
+		sheet, err := loadAsepriteJSON(path)
+		if err != nil {
+			return nil, err
+		}
+		// Load the image before the temporary directory is removed.
+		return sheet, sheet.preload()
+	}
+	return loadAsepriteJSON(path)
+}
+
+// Tags returns the tags of the sheet. A sheet with no tags has a single unnamed tag with all the
+// frames.
+func (s *AsepriteSheet) Tags() []AsepriteTag {
+	if len(s.Meta.FrameTags) == 0 {
+		return []AsepriteTag{{From: 0, To: len(s.Frames) - 1}}
+	}
+	return s.Meta.FrameTags
+}
+
+// SpriteSheet returns a sprite sheet with a row for each tag. The columns of each row are the
+// frames of the tag in playing order.
+func (s *AsepriteSheet) SpriteSheet() (*pctk.SpriteSheet, error) {
+	if len(s.Frames) == 0 {
+		return nil, fmt.Errorf("no frames in aseprite sheet")
+	}
+	sheet := s.loaded
+	if sheet == nil {
+		if err := s.preload(); err != nil {
+			return nil, err
+		}
+		sheet = s.loaded
+	}
+	for _, tag := range s.Tags() {
+		indexes, err := s.tagFrames(tag)
+		if err != nil {
+			return nil, err
+		}
+		row := make([]pctk.SpriteFrame, len(indexes))
+		for i, index := range indexes {
+			f := s.Frames[index]
+			row[i] = pctk.SpriteFrame{
+				Rect:   pctk.NewRect(f.Frame.X, f.Frame.Y, f.Frame.W, f.Frame.H),
+				Offset: pctk.NewPos(f.SpriteSourceSize.X, f.SpriteSourceSize.Y),
+			}
+		}
+		sheet.AddRow(row...)
+	}
+	return sheet, nil
+}
+
+// Costume returns a costume with an animation for each tag. Tags are named after the action and
+// the direction, as in "walk_right", "idle_down" or "pick_up_left". Tags without a direction, as in
+// "blink", are custom actions facing right. Custom actions may also be named after their code
+// instead, as in "129". If a left animation is missing, its right counterpart is used flipped.
+func (s *AsepriteSheet) Costume() (*pctk.Costume, error) {
+	sprites, err := s.SpriteSheet()
+	if err != nil {
+		return nil, err
+	}
+	costume := pctk.NewCostume(sprites)
+
+	anims := make(map[pctk.CostumeAction]*pctk.Animation)
+	for row, tag := range s.Tags() {
+		action, dir := splitTagName(tag.Name)
+		act, err := parseCostumeAction(costume, action, dir)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tag %q: %w", tag.Name, err)
+		}
+		anims[act] = s.animation(row, tag, false)
+	}
+	for row, tag := range s.Tags() {
+		action, dir := splitTagName(tag.Name)
+		if !strings.EqualFold(dir, "right") {
+			continue
+		}
+		left, _ := parseCostumeAction(costume, action, "left")
+		if _, ok := anims[left]; !ok {
+			anims[left] = s.animation(row, tag, true)
+		}
+	}
+	for act, anim := range anims {
+		costume.WithAnimation(act, anim)
+	}
+	return costume, nil
+}
+
+// splitTagName splits a tag name into the action and the direction after its last underscore. If
+// the name does not end in a direction, the whole name is the action and the direction is the
+// default one of the actors.
+func splitTagName(name string) (action, dir string) {
+	if i := strings.LastIndex(name, "_"); i >= 0 {
+		if _, err := pctk.ParseDirection(name[i+1:]); err == nil {
+			return name[:i], name[i+1:]
+		}
+	}
+	return name, pctk.DefaultActorDirection.String()
+}
+
+func (s *AsepriteSheet) animation(row int, tag AsepriteTag, flip bool) *pctk.Animation {
+	anim := pctk.NewAnimation().Flip(flip)
+	indexes, _ := s.tagFrames(tag)
+	for col, index := range indexes {
+		delay := time.Duration(s.Frames[index].Duration) * time.Millisecond
+		anim.AddFrames(delay, row, col)
+	}
+	return anim
+}
+
+// tagFrames returns the indexes of the frames of a tag in playing order.
+func (s *AsepriteSheet) tagFrames(tag AsepriteTag) ([]int, error) {
+	if tag.From < 0 || tag.To >= len(s.Frames) || tag.From > tag.To {
+		return nil, fmt.Errorf("invalid frame range %d-%d in tag %q", tag.From, tag.To, tag.Name)
+	}
+	var indexes []int
+	for i := tag.From; i <= tag.To; i++ {
+		indexes = append(indexes, i)
+	}
+	switch tag.Direction {
+	case "", "forward":
+	case "reverse":
+		for i, j := 0, len(indexes)-1; i < j; i, j = i+1, j-1 {
+			indexes[i], indexes[j] = indexes[j], indexes[i]
+		}
+	case "pingpong":
+		for i := tag.To - 1; i > tag.From; i-- {
+			indexes = append(indexes, i)
+		}
+	default:
+		return nil, fmt.Errorf("unknown direction %q in tag %q", tag.Direction, tag.Name)
+	}
+	return indexes, nil
+}
+
+func (s *AsepriteSheet) preload() error {
+	path := s.imagePath()
+	if _, err := os.Stat(path); err != nil {
+		return err
+	}
+	size := pctk.Size{}
+	if len(s.Frames) > 0 {
+		size = pctk.NewSize(s.Frames[0].SourceSize.W, s.Frames[0].SourceSize.H)
+	}
+	s.loaded = pctk.LoadSpriteSheetFromFile(path, size)
+	return nil
+}
+
+// asepriteSources returns the files an Aseprite manifest entry depends on.
+func asepriteSources(path string) ([]string, error) {
+	if isAsepriteFile(path) {
+		return []string{path}, nil
+	}
+	sheet, err := loadAsepriteJSON(path)
+	if err != nil {
+		return nil, err
+	}
+	return []string{path, sheet.imagePath()}, nil
+}
+
+func (s *AsepriteSheet) imagePath() string {
+	if filepath.IsAbs(s.Meta.Image) {
+		return s.Meta.Image
+	}
+	return filepath.Join(s.dir, s.Meta.Image)
+}
+
+func loadAsepriteJSON(path string) (*AsepriteSheet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	sheet := &AsepriteSheet{dir: filepath.Dir(path)}
+	if err := json.Unmarshal(data, sheet); err != nil {
+		return nil, fmt.Errorf("error decoding %s: %w", path, err)
+	}
+	return sheet, nil
+}
+
+func exportAseprite(path, dir string) (string, error) {
+	command := AsepriteCommand
+	if env := os.Getenv("ASEPRITE"); env != "" {
+		command = env
+	}
+	data := filepath.Join(dir, "sheet.json")
+	cmd := exec.Command(command, "--batch", path,
+		"--sheet", filepath.Join(dir, "sheet.png"),
+		"--data", data,
+		"--format", "json-array",
+		"--list-tags",
+		"--trim",
+	)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return "", fmt.Errorf("error exporting %s with %s: %w\n%s", path, command, err, out)
+	}
+	return data, nil
+}
+
+func isAsepriteFile(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == ".aseprite" || ext == ".ase"
+}
@@ -0,0 +1,101 @@
+package pack
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/apoloval/pctk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAsepriteSheet_tagFrames(t *testing.T) {
+	sheet := &AsepriteSheet{Frames: make(AsepriteFrames, 6)}
+	tests := []struct {
+		name    string
+		tag     AsepriteTag
+		want    []int
+		wantErr bool
+	}{
+		{name: "no direction", tag: AsepriteTag{From: 1, To: 3}, want: []int{1, 2, 3}},
+		{name: "forward", tag: AsepriteTag{From: 0, To: 2, Direction: "forward"}, want: []int{0, 1, 2}},
+		{name: "reverse", tag: AsepriteTag{From: 2, To: 5, Direction: "reverse"}, want: []int{5, 4, 3, 2}},
+		{name: "pingpong", tag: AsepriteTag{From: 1, To: 4, Direction: "pingpong"}, want: []int{1, 2, 3, 4, 3, 2}},
+		{name: "pingpong of two frames", tag: AsepriteTag{From: 0, To: 1, Direction: "pingpong"}, want: []int{0, 1}},
+		{name: "single frame", tag: AsepriteTag{From: 5, To: 5, Direction: "reverse"}, want: []int{5}},
+		{name: "out of range", tag: AsepriteTag{From: 4, To: 6}, wantErr: true},
+		{name: "negative", tag: AsepriteTag{From: -1, To: 2}, wantErr: true},
+		{name: "inverted range", tag: AsepriteTag{From: 3, To: 1}, wantErr: true},
+		{name: "unknown direction", tag: AsepriteTag{From: 0, To: 2, Direction: "sideways"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := sheet.tagFrames(tt.tag)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestAsepriteFrames_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []int
+	}{
+		{
+			name: "array",
+			data: `[{"duration": 100}, {"duration": 200}, {"duration": 300}]`,
+			want: []int{100, 200, 300},
+		},
+		{
+			name: "hash in export order",
+			data: `{"walk 2.png": {"duration": 100}, "walk 10.png": {"duration": 200}, "walk 1.png": {"duration": 300}}`,
+			want: []int{100, 200, 300},
+		},
+		{
+			name: "empty hash",
+			data: `{}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var frames AsepriteFrames
+			require.NoError(t, json.Unmarshal([]byte(tt.data), &frames))
+			var durations []int
+			for _, f := range frames {
+				durations = append(durations, f.Duration)
+			}
+			assert.Equal(t, tt.want, durations)
+		})
+	}
+}
+
+func TestSplitTagName(t *testing.T) {
+	tests := []struct {
+		name       string
+		wantAction string
+		wantDir    string
+	}{
+		{name: "walk_right", wantAction: "walk", wantDir: "right"},
+		{name: "idle_Down", wantAction: "idle", wantDir: "Down"},
+		{name: "pick_up_right", wantAction: "pick_up", wantDir: "right"},
+		{name: "blink", wantAction: "blink", wantDir: "Right"},
+		{name: "look_around", wantAction: "look_around", wantDir: "Right"},
+		{name: "129", wantAction: "129", wantDir: "Right"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			action, dir := splitTagName(tt.name)
+			assert.Equal(t, tt.wantAction, action)
+			assert.Equal(t, tt.wantDir, dir)
+
+			_, err := parseCostumeAction(pctk.NewCostume(nil), action, dir)
+			assert.NoError(t, err)
+		})
+	}
+}
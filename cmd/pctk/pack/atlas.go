@@ -0,0 +1,216 @@
+package pack
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/apoloval/pctk"
+)
+
+// AtlasPadding is the number of transparent pixels left between sprites in an atlas.
+const AtlasPadding = 1
+
+// AtlasRow is a row of sprites of an atlas, made of individual frame images. The frames are the
+// columns of the row, sorted by file name.
+type AtlasRow struct {
+	// Source is a directory with PNG files, a glob pattern or a single PNG file.
+	Source string
+
+	// Pivot is the point of each frame image that is aligned with the bottom-center of the sprite
+	// frame. If omitted, the bottom-center of the frame image is used.
+	Pivot *struct {
+		X, Y int
+	}
+}
+
+type atlasFrame struct {
+	img    image.Image
+	trim   image.Rectangle
+	pivot  image.Point
+	row    int
+	col    int
+	sprite pctk.SpriteFrame
+}
+
+// LoadAtlas packs the frame images of the given rows into an atlas sprite sheet. Transparent
+// borders are trimmed from every frame. If the frame size is zero, the size of the largest frame
+// image is used.
+func LoadAtlas(workingDir string, frameSize pctk.Size, rows []AtlasRow) (*pctk.SpriteSheet, error) {
+	a, err := buildAtlas(workingDir, frameSize, rows)
+	if err != nil {
+		return nil, err
+	}
+	var data bytes.Buffer
+	if err := png.Encode(&data, a.image); err != nil {
+		return nil, err
+	}
+	sheet := pctk.NewSpriteSheetFromPNG(data.Bytes(), a.frameSize)
+	for _, row := range a.rows {
+		sheet.AddRow(row...)
+	}
+	return sheet, nil
+}
+
+// atlas is the image of an atlas along with the location of its sprites.
+type atlas struct {
+	image     *image.NRGBA
+	frameSize pctk.Size
+	rows      [][]pctk.SpriteFrame
+}
+
+// buildAtlas loads the frame images of the given rows and packs them into an atlas image, as
+// described by LoadAtlas.
+func buildAtlas(workingDir string, frameSize pctk.Size, rows []AtlasRow) (*atlas, error) {
+	var frames []*atlasFrame
+	rowSizes := make([]int, len(rows))
+	canvas := image.Point{}
+	for i, row := range rows {
+		files, err := expandSource(filepath.Join(workingDir, row.Source))
+		if err != nil {
+			return nil, err
+		}
+		if len(files) == 0 {
+			return nil, fmt.Errorf("no frame images found in %s", row.Source)
+		}
+		for j, file := range files {
+			img, err := loadPNG(file)
+			if err != nil {
+				return nil, err
+			}
+			b := img.Bounds()
+			pivot := image.Pt(b.Dx()/2, b.Dy())
+			if row.Pivot != nil {
+				pivot = image.Pt(row.Pivot.X, row.Pivot.Y)
+			}
+			frames = append(frames, &atlasFrame{
+				img:   img,
+				trim:  trimTransparent(img),
+				pivot: pivot,
+				row:   i,
+				col:   j,
+			})
+			canvas.X = max(canvas.X, b.Dx())
+			canvas.Y = max(canvas.Y, b.Dy())
+		}
+		rowSizes[i] = len(files)
+	}
+	if frameSize.W == 0 || frameSize.H == 0 {
+		frameSize = pctk.Size{W: canvas.X, H: canvas.Y}
+	}
+
+	for _, f := range frames {
+		trimOffset := f.trim.Min.Sub(f.img.Bounds().Min)
+		f.sprite.Offset = pctk.NewPos(
+			frameSize.W/2-f.pivot.X+trimOffset.X,
+			frameSize.H-f.pivot.Y+trimOffset.Y,
+		)
+	}
+
+	a := &atlas{image: packFrames(frames), frameSize: frameSize, rows: make([][]pctk.SpriteFrame, len(rows))}
+	for i, size := range rowSizes {
+		a.rows[i] = make([]pctk.SpriteFrame, size)
+	}
+	for _, f := range frames {
+		a.rows[f.row][f.col] = f.sprite
+	}
+	return a, nil
+}
+
+// packFrames places the trimmed frames in shelves of an atlas image, tallest frames first, and
+// sets the rectangle of each sprite accordingly.
+func packFrames(frames []*atlasFrame) *image.NRGBA {
+	order := slices.Clone(frames)
+	sort.SliceStable(order, func(i, j int) bool {
+		return order[i].trim.Dy() > order[j].trim.Dy()
+	})
+
+	area, widest := 0, 1
+	for _, f := range frames {
+		area += (f.trim.Dx() + AtlasPadding) * (f.trim.Dy() + AtlasPadding)
+		widest = max(widest, f.trim.Dx())
+	}
+	width := 1
+	for width < int(math.Ceil(math.Sqrt(float64(area)))) {
+		width *= 2
+	}
+	width = max(width, widest)
+
+	x, y, shelf := 0, 0, 0
+	for _, f := range order {
+		w, h := f.trim.Dx(), f.trim.Dy()
+		if x+w > width {
+			x, y, shelf = 0, y+shelf+AtlasPadding, 0
+		}
+		f.sprite.Rect = pctk.Rectangle{Pos: pctk.NewPos(x, y), Size: pctk.NewSize(w, h)}
+		x += w + AtlasPadding
+		shelf = max(shelf, h)
+	}
+
+	atlas := image.NewNRGBA(image.Rect(0, 0, width, max(y+shelf, 1)))
+	for _, f := range frames {
+		r := f.sprite.Rect
+		dst := image.Rect(r.Pos.X, r.Pos.Y, r.Pos.X+r.Size.W, r.Pos.Y+r.Size.H)
+		draw.Draw(atlas, dst, f.img, f.trim.Min, draw.Src)
+	}
+	return atlas
+}
+
+// trimTransparent returns the bounds of the image without its fully transparent borders.
+func trimTransparent(img image.Image) image.Rectangle {
+	b := img.Bounds()
+	trim := image.Rectangle{Min: b.Max, Max: b.Min}
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if _, _, _, a := img.At(x, y).RGBA(); a == 0 {
+				continue
+			}
+			trim.Min.X = min(trim.Min.X, x)
+			trim.Min.Y = min(trim.Min.Y, y)
+			trim.Max.X = max(trim.Max.X, x+1)
+			trim.Max.Y = max(trim.Max.Y, y+1)
+		}
+	}
+	if trim.Empty() {
+		return image.Rectangle{Min: b.Min, Max: b.Min}
+	}
+	return trim
+}
+
+func loadPNG(path string) (image.Image, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	img, err := png.Decode(file)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding %s: %w", path, err)
+	}
+	return img, nil
+}
+
+// expandSource returns the files a source path refers to. Directories are expanded to the PNG
+// files they contain, and glob patterns to the files they match. Files are sorted by name.
+func expandSource(path string) ([]string, error) {
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		path = filepath.Join(path, "*.png")
+	} else if !strings.ContainsAny(path, "*?[") {
+		return []string{path}, nil
+	}
+	files, err := filepath.Glob(path)
+	if err != nil {
+		return nil, err
+	}
+	slices.Sort(files)
+	return files, nil
+}
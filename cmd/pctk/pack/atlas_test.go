@@ -0,0 +1,162 @@
+package pack
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/apoloval/pctk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testFrame returns an image of the given size that is transparent but in the opaque rectangle.
+func testFrame(w, h int, opaque image.Rectangle) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := opaque.Min.Y; y < opaque.Max.Y; y++ {
+		for x := opaque.Min.X; x < opaque.Max.X; x++ {
+			img.Set(x, y, color.NRGBA{R: uint8(x), G: uint8(y), B: 0xFF, A: 0xFF})
+		}
+	}
+	return img
+}
+
+func writeFrame(t *testing.T, path string, img image.Image) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	file, err := os.Create(path)
+	require.NoError(t, err)
+	defer file.Close()
+	require.NoError(t, png.Encode(file, img))
+}
+
+func TestTrimTransparent(t *testing.T) {
+	tests := []struct {
+		name string
+		img  image.Image
+		want image.Rectangle
+	}{
+		{name: "opaque", img: testFrame(8, 6, image.Rect(0, 0, 8, 6)), want: image.Rect(0, 0, 8, 6)},
+		{name: "transparent borders", img: testFrame(8, 6, image.Rect(2, 1, 5, 6)), want: image.Rect(2, 1, 5, 6)},
+		{name: "single pixel", img: testFrame(8, 6, image.Rect(7, 0, 8, 1)), want: image.Rect(7, 0, 8, 1)},
+		{name: "fully transparent", img: testFrame(8, 6, image.Rectangle{}), want: image.Rect(0, 0, 0, 0)},
+		{
+			name: "sub image",
+			img:  testFrame(8, 6, image.Rect(3, 2, 6, 4)).SubImage(image.Rect(2, 2, 8, 6)),
+			want: image.Rect(3, 2, 6, 4),
+		},
+		{
+			name: "fully transparent sub image",
+			img:  testFrame(8, 6, image.Rect(0, 0, 2, 2)).SubImage(image.Rect(4, 3, 8, 6)),
+			want: image.Rect(4, 3, 4, 3),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, trimTransparent(tt.img))
+		})
+	}
+}
+
+func TestPackFrames(t *testing.T) {
+	var frames []*atlasFrame
+	for i, opaque := range []image.Rectangle{
+		image.Rect(0, 0, 16, 24),
+		image.Rect(4, 8, 12, 24),
+		image.Rect(0, 20, 16, 24),
+		image.Rect(6, 6, 7, 7),
+		{},
+		image.Rect(2, 2, 14, 10),
+	} {
+		img := testFrame(16, 24, opaque)
+		frames = append(frames, &atlasFrame{img: img, trim: trimTransparent(img), col: i})
+	}
+	atlas := packFrames(frames)
+
+	rect := func(f *atlasFrame) image.Rectangle {
+		r := f.sprite.Rect
+		return image.Rect(r.Pos.X, r.Pos.Y, r.Pos.X+r.Size.W, r.Pos.Y+r.Size.H)
+	}
+	padded := func(r image.Rectangle) image.Rectangle {
+		return image.Rectangle{Min: r.Min, Max: r.Max.Add(image.Pt(AtlasPadding, AtlasPadding))}
+	}
+	for i, f := range frames {
+		r := rect(f)
+		assert.Equal(t, f.trim.Size(), r.Size(), "frame %d keeps its trimmed size", i)
+		assert.True(t, r.In(atlas.Bounds()), "frame %d is inside the atlas", i)
+		for y := 0; y < r.Dy(); y++ {
+			for x := 0; x < r.Dx(); x++ {
+				assert.Equal(t, f.img.At(f.trim.Min.X+x, f.trim.Min.Y+y), atlas.At(r.Min.X+x, r.Min.Y+y))
+			}
+		}
+
+		for j, other := range frames[i+1:] {
+			o := rect(other)
+			if r.Empty() || o.Empty() {
+				continue
+			}
+			assert.False(t, padded(r).Overlaps(o), "frames %d and %d are padded apart", i, i+1+j)
+			assert.False(t, padded(o).Overlaps(r), "frames %d and %d are padded apart", i+1+j, i)
+		}
+	}
+}
+
+func TestBuildAtlas(t *testing.T) {
+	dir := t.TempDir()
+	writeFrame(t, filepath.Join(dir, "walk/1.png"), testFrame(16, 24, image.Rect(4, 8, 12, 24)))
+	writeFrame(t, filepath.Join(dir, "walk/2.png"), testFrame(16, 24, image.Rect(0, 0, 16, 24)))
+	writeFrame(t, filepath.Join(dir, "idle.png"), testFrame(16, 24, image.Rect(4, 8, 12, 24)))
+	writeFrame(t, filepath.Join(dir, "blank.png"), testFrame(16, 24, image.Rectangle{}))
+	rows := []AtlasRow{
+		{Source: "walk"},
+		{Source: "idle.png", Pivot: &struct{ X, Y int }{X: 8, Y: 20}},
+		{Source: "blank.png"},
+	}
+
+	tests := []struct {
+		name      string
+		frameSize pctk.Size
+		want      pctk.Size
+		offsets   [][]pctk.Position
+	}{
+		{
+			name:    "frame size of the largest image",
+			want:    pctk.NewSize(16, 24),
+			offsets: [][]pctk.Position{{{X: 4, Y: 8}, {X: 0, Y: 0}}, {{X: 4, Y: 12}}, {{X: 0, Y: 0}}},
+		},
+		{
+			name:      "explicit frame size",
+			frameSize: pctk.NewSize(32, 32),
+			want:      pctk.NewSize(32, 32),
+			offsets:   [][]pctk.Position{{{X: 12, Y: 16}, {X: 8, Y: 8}}, {{X: 12, Y: 20}}, {{X: 8, Y: 8}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := buildAtlas(dir, tt.frameSize, rows)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, a.frameSize)
+
+			var offsets [][]pctk.Position
+			for _, row := range a.rows {
+				var rowOffsets []pctk.Position
+				for _, frame := range row {
+					rowOffsets = append(rowOffsets, frame.Offset)
+				}
+				offsets = append(offsets, rowOffsets)
+			}
+			assert.Equal(t, tt.offsets, offsets)
+			assert.Equal(t, pctk.NewSize(8, 16), a.rows[0][0].Rect.Size)
+			assert.Equal(t, pctk.NewSize(0, 0), a.rows[2][0].Rect.Size)
+		})
+	}
+}
+
+func TestBuildAtlas_NoFrames(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "empty"), 0o755))
+	_, err := buildAtlas(dir, pctk.Size{}, []AtlasRow{{Source: "empty"}})
+	assert.EqualError(t, err, "no frame images found in empty")
+}
@@ -0,0 +1,132 @@
+package pack
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/apoloval/pctk"
+	"gopkg.in/yaml.v3"
+)
+
+// Cache maps the resources of a previously packed output to the hash of the sources they were
+// encoded from. Resources whose hash did not change are copied from the previous output instead of
+// being encoded again.
+type Cache struct {
+	Hashes map[pctk.ResourceID]string `json:"hashes"`
+
+	loader *pctk.ResourceFileLoader
+	pkg    pctk.ResourcePackage
+}
+
+// NewCache creates a new empty cache.
+func NewCache() *Cache {
+	return &Cache{Hashes: make(map[pctk.ResourceID]string)}
+}
+
+// LoadCache loads the cache of the given output files. An empty cache is returned if there is no
+// previous output or its cache cannot be read.
+func LoadCache(output string) *Cache {
+	cache := NewCache()
+	data, err := os.ReadFile(cachePath(output))
+	if err != nil {
+		return cache
+	}
+	if err := json.Unmarshal(data, cache); err != nil || cache.Hashes == nil {
+		return NewCache()
+	}
+	cache.loader = pctk.NewResourceFileLoader(filepath.Dir(output))
+	cache.pkg = pctk.ResourcePackage(filepath.Base(output))
+	return cache
+}
+
+// Lookup returns the encoded data of the resource with the given ID if it was packed from sources
+// with the given hash.
+func (c *Cache) Lookup(id pctk.ResourceID, hash string) ([]byte, bool) {
+	if c.loader == nil || c.Hashes[id] != hash {
+		return nil, false
+	}
+	data, err := c.loader.LoadRaw(pctk.NewResourceRef(c.pkg, id))
+	if err != nil {
+		return nil, false
+	}
+	return data, true
+}
+
+// Save writes the cache for the given output files.
+func (c *Cache) Save(output string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(cachePath(output), data, 0o644)
+}
+
+func cachePath(output string) string {
+	return output + ".cache"
+}
+
+// hashFiles returns the hash of the contents of the given files.
+func hashFiles(paths ...string) (string, error) {
+	h := sha256.New()
+	fmt.Fprintf(h, "pctk:%d\n", pctk.ResourceFormatVersion)
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(h, "%d\n", len(data))
+		h.Write(data)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// manifestSourceKeys are the manifest keys whose values are paths to source files, along with the
+// function that returns the files each path depends on.
+var manifestSourceKeys = map[string]func(path string) ([]string, error){
+	"source":   expandSource,
+	"sheet":    expandSource,
+	"aseprite": asepriteSources,
+}
+
+// manifestSources returns the manifest file followed by the source files it refers to. They are
+// found without decoding the manifest data, since decoding is what loads the sources.
+func manifestSources(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	sources := []string{path}
+	var visit func(n *yaml.Node) error
+	visit = func(n *yaml.Node) error {
+		for i, child := range n.Content {
+			if n.Kind == yaml.MappingNode && i%2 == 1 {
+				key := n.Content[i-1].Value
+				if expand := manifestSourceKeys[key]; expand != nil && child.Kind == yaml.ScalarNode {
+					files, err := expand(filepath.Join(filepath.Dir(path), child.Value))
+					if err != nil {
+						return err
+					}
+					sources = append(sources, files...)
+					continue
+				}
+			}
+			if err := visit(child); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := visit(&doc); err != nil {
+		return nil, err
+	}
+	return sources, nil
+}
@@ -0,0 +1,179 @@
+package pack
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/apoloval/pctk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeFiles writes the given files, as path: content, in dir.
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	for path, content := range files {
+		path = filepath.Join(dir, path)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+}
+
+// packFixture packs a Lua script and a script manifest, and returns the source directory and the
+// output files.
+func packFixture(t *testing.T) (src string, output string) {
+	dir := t.TempDir()
+	src = filepath.Join(dir, "src")
+	output = filepath.Join(dir, "game")
+	writeFiles(t, src, map[string]string{
+		"scripts/boot.lua":  "print('boot v1')",
+		"scripts/intro.yml": "type: script\ndata:\n  code: print('intro v1')\n",
+	})
+	require.NoError(t, do(src, output, false))
+	return src, output
+}
+
+// packedCode returns the code of the script with the given ID in the output files.
+func packedCode(output string, id pctk.ResourceID) string {
+	loader := pctk.NewResourceFileLoader(filepath.Dir(output))
+	script := loader.LoadScript(pctk.NewResourceRef(pctk.ResourcePackage(filepath.Base(output)), id))
+	return string(script.Code)
+}
+
+// markUnchanged updates the cache of the output as if the resource had been packed from its current
+// sources, so reusing it from the cache keeps the previous code in the output.
+func markUnchanged(t *testing.T, output string, id pctk.ResourceID, sources ...string) {
+	hash, err := hashFiles(sources...)
+	require.NoError(t, err)
+	cache := LoadCache(output)
+	cache.Hashes[id] = hash
+	require.NoError(t, cache.Save(output))
+}
+
+func TestDo_ReusesUnchangedResources(t *testing.T) {
+	src, output := packFixture(t)
+	boot := filepath.Join(src, "scripts/boot.lua")
+	writeFiles(t, src, map[string]string{"scripts/boot.lua": "print('boot v2')"})
+	markUnchanged(t, output, "scripts/boot", boot)
+
+	require.NoError(t, do(src, output, false))
+	assert.Equal(t, "print('boot v1')", packedCode(output, "scripts/boot"))
+	assert.Equal(t, "print('intro v1')", packedCode(output, "scripts/intro"))
+}
+
+func TestDo_EncodesChangedResources(t *testing.T) {
+	src, output := packFixture(t)
+	writeFiles(t, src, map[string]string{
+		"scripts/boot.lua":  "print('boot v2')",
+		"scripts/intro.yml": "type: script\ndata:\n  code: print('intro v2')\n",
+	})
+
+	require.NoError(t, do(src, output, false))
+	assert.Equal(t, "print('boot v2')", packedCode(output, "scripts/boot"))
+	assert.Equal(t, "print('intro v2')", packedCode(output, "scripts/intro"))
+}
+
+func TestDo_ForceIgnoresCache(t *testing.T) {
+	src, output := packFixture(t)
+	boot := filepath.Join(src, "scripts/boot.lua")
+	writeFiles(t, src, map[string]string{"scripts/boot.lua": "print('boot v2')"})
+	markUnchanged(t, output, "scripts/boot", boot)
+
+	require.NoError(t, do(src, output, true))
+	assert.Equal(t, "print('boot v2')", packedCode(output, "scripts/boot"))
+}
+
+func TestDo_FormatVersionInvalidatesCache(t *testing.T) {
+	src, output := packFixture(t)
+	boot := filepath.Join(src, "scripts/boot.lua")
+	writeFiles(t, src, map[string]string{"scripts/boot.lua": "print('boot v2')"})
+	markUnchanged(t, output, "scripts/boot", boot)
+
+	// Pretend the previous output was packed by a former version of the format.
+	for _, ext := range []string{".idx", ".dat"} {
+		data, err := os.ReadFile(output + ext)
+		require.NoError(t, err)
+		binary.LittleEndian.PutUint16(data[8:], pctk.ResourceFormatVersion-1)
+		require.NoError(t, os.WriteFile(output+ext, data, 0o644))
+	}
+
+	require.NoError(t, do(src, output, false))
+	assert.Equal(t, "print('boot v2')", packedCode(output, "scripts/boot"))
+}
+
+func TestCache_Lookup(t *testing.T) {
+	src, output := packFixture(t)
+	hash, err := hashFiles(filepath.Join(src, "scripts/boot.lua"))
+	require.NoError(t, err)
+
+	cache := LoadCache(output)
+	data, ok := cache.Lookup("scripts/boot", hash)
+	assert.True(t, ok)
+	assert.NotEmpty(t, data)
+
+	_, ok = cache.Lookup("scripts/boot", "other")
+	assert.False(t, ok)
+	_, ok = cache.Lookup("scripts/other", hash)
+	assert.False(t, ok)
+	_, ok = LoadCache(filepath.Join(t.TempDir(), "missing")).Lookup("scripts/boot", hash)
+	assert.False(t, ok)
+}
+
+// atlasFixture are the files of a costume manifest whose atlas rows refer to a directory and to a
+// single frame image.
+var atlasFixture = map[string]string{
+	"costumes/ego.yml": `type: costume
+data:
+  sprites:
+    atlas:
+      - source: ego/walk
+      - source: ego/idle.png
+`,
+	"costumes/ego/walk/1.png": "walk 1",
+	"costumes/ego/walk/2.png": "walk 2",
+	"costumes/ego/idle.png":   "idle",
+}
+
+func TestManifestSources(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, atlasFixture)
+	manifest := filepath.Join(dir, "costumes/ego.yml")
+
+	sources, err := manifestSources(manifest)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		manifest,
+		filepath.Join(dir, "costumes/ego/walk/1.png"),
+		filepath.Join(dir, "costumes/ego/walk/2.png"),
+		filepath.Join(dir, "costumes/ego/idle.png"),
+	}, sources)
+}
+
+func TestManifestSources_Changes(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+	}{
+		{name: "changed manifest", files: map[string]string{"costumes/ego.yml": "type: costume\n"}},
+		{name: "changed source file", files: map[string]string{"costumes/ego/idle.png": "idle 2"}},
+		{name: "new atlas entry", files: map[string]string{"costumes/ego/walk/3.png": "walk 3"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, atlasFixture)
+			hash := func() string {
+				sources, err := manifestSources(filepath.Join(dir, "costumes/ego.yml"))
+				require.NoError(t, err)
+				h, err := hashFiles(sources...)
+				require.NoError(t, err)
+				return h
+			}
+
+			before := hash()
+			writeFiles(t, dir, tt.files)
+			assert.NotEqual(t, before, hash())
+		})
+	}
+}
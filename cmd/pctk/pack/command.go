@@ -1,23 +1,51 @@
 package pack
 
 import (
+	"errors"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
 	"github.com/spf13/cobra"
 )
 
-var output string
+var (
+	output    string
+	force     bool
+	watchMode bool
+	verifyPkg bool
+)
 
 var Command = &cobra.Command{
 	Use:   "pack [src]",
 	Short: "pack game resources into a file",
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		rl.SetTraceLogLevel(rl.LogNone)
 		src := args[0]
-		return do(src, output)
+		if verifyPkg {
+			if watchMode {
+				return errors.New("--verify and --watch cannot be used together")
+			}
+			cmd.SilenceUsage = true
+			return verify(src, output)
+		}
+		if watchMode {
+			return watch(src, output, force)
+		}
+		return do(src, output, force)
 	},
 }
 
 func init() {
-	Command.LocalFlags().StringVarP(
+	Command.Flags().StringVarP(
 		&output, "output", "o", "resources", "output files (.idx/.dat suffixes will be added)",
 	)
+	Command.Flags().BoolVarP(
+		&force, "force", "f", false, "encode all resources, ignoring the cache of the previous output",
+	)
+	Command.Flags().BoolVarP(
+		&watchMode, "watch", "w", false, "watch the sources and pack them again on every change",
+	)
+	Command.Flags().BoolVar(
+		&verifyPkg, "verify", false, "pack the sources from scratch and compare them with the output",
+	)
 }
@@ -5,7 +5,6 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
-	"time"
 
 	"github.com/apoloval/pctk"
 	"gopkg.in/yaml.v3"
@@ -25,75 +24,88 @@ func NewCostumeData(workingDir string) *CostumeData {
 
 func (d *CostumeData) UnmarshalYAML(n *yaml.Node) error {
 	var data struct {
-		Sprites struct {
+		Aseprite string
+		Sprites  struct {
 			Sheet  string
+			Atlas  []AtlasRow
 			Width  uint
 			Height uint
 		}
 		Animations []struct {
-			Action string
-			Dir    string
-			Flip   bool
-			Frames []struct {
-				Row      int
-				Columns  []int
-				Duration int
-			}
+			Action        string
+			Dir           string
+			animationData `yaml:",inline"`
 		}
 	}
 	if err := n.Decode(&data); err != nil {
 		return err
 	}
 
-	sprites := pctk.LoadSpriteSheetFromFile(
-		filepath.Join(d.workingDir, data.Sprites.Sheet),
-		pctk.Size{W: int(data.Sprites.Width), H: int(data.Sprites.Height)},
-	)
-	d.Resource = pctk.NewCostume(sprites)
-
-	for _, anim := range data.Animations {
-		dir := func() pctk.Direction {
-			switch strings.ToLower(anim.Dir) {
-			case "right":
-				return pctk.DirRight
-			case "left":
-				return pctk.DirLeft
-			case "up":
-				return pctk.DirUp
-			case "down":
-				return pctk.DirDown
-			default:
-				panic(fmt.Sprintf("invalid direction %q", anim.Dir))
-			}
+	if data.Aseprite != "" {
+		sheet, err := LoadAseprite(filepath.Join(d.workingDir, data.Aseprite))
+		if err != nil {
+			return err
 		}
+		d.Resource, err = sheet.Costume()
+		return err
+	}
 
-		a := pctk.NewAnimation().Flip(anim.Flip)
-		for _, frame := range anim.Frames {
-			a.AddFrames(
-				time.Duration(frame.Duration)*time.Millisecond,
-				frame.Row,
-				frame.Columns...,
-			)
+	frameSize := pctk.Size{W: int(data.Sprites.Width), H: int(data.Sprites.Height)}
+	var sprites *pctk.SpriteSheet
+	if len(data.Sprites.Atlas) > 0 {
+		var err error
+		if sprites, err = LoadAtlas(d.workingDir, frameSize, data.Sprites.Atlas); err != nil {
+			return err
 		}
+	} else {
+		sprites = pctk.LoadSpriteSheetFromFile(filepath.Join(d.workingDir, data.Sprites.Sheet), frameSize)
+	}
+	d.Resource = pctk.NewCostume(sprites)
 
-		var act pctk.CostumeAction
-		switch strings.ToLower(anim.Action) {
-		case "idle":
-			act = pctk.CostumeIdle(dir())
-		case "speak":
-			act = pctk.CostumeSpeak(dir())
-		case "walk":
-			act = pctk.CostumeWalk(dir())
-		default:
-			code, err := strconv.Atoi(anim.Action)
-			if err != nil {
-				err := fmt.Errorf("neither a default action nor a custom action code: %w", err)
-				return fmt.Errorf("invalid action %q: %w", anim.Action, err)
-			}
-			act = pctk.CostumeAction(code)
+	for _, anim := range data.Animations {
+		act, err := parseCostumeAction(d.Resource, anim.Action, anim.Dir)
+		if err != nil {
+			return err
+		}
+		a, err := anim.animation()
+		if err != nil {
+			return fmt.Errorf("animation %s %s: %w", anim.Action, anim.Dir, err)
 		}
 		d.Resource.WithAnimation(act, a)
 	}
 
 	return nil
 }
+
+// parseCostumeAction parses a costume action from its name and direction. The name is either a
+// default action (idle, speak or walk), a custom action code from pctk.CostumeCustomAction, or the
+// name of a custom action, which is declared in the costume.
+func parseCostumeAction(costume *pctk.Costume, action, dir string) (pctk.CostumeAction, error) {
+	if code, err := strconv.Atoi(action); err == nil {
+		if code < int(pctk.CostumeCustomAction) || code > 0xFF {
+			return 0, fmt.Errorf("invalid action code %d, custom codes go from %d to 255",
+				code, pctk.CostumeCustomAction)
+		}
+		return pctk.CostumeAction(code), nil
+	}
+	d, err := pctk.ParseDirection(dir)
+	if err != nil {
+		return 0, err
+	}
+	switch name := strings.ToLower(action); name {
+	case "idle":
+		return pctk.CostumeIdle(d), nil
+	case "speak":
+		return pctk.CostumeSpeak(d), nil
+	case "walk":
+		return pctk.CostumeWalk(d), nil
+	case "":
+		return 0, fmt.Errorf("missing action name")
+	default:
+		act, err := costume.DeclareNamedAction(name, d)
+		if err != nil {
+			return 0, fmt.Errorf("invalid action %q: %w", action, err)
+		}
+		return act, nil
+	}
+}
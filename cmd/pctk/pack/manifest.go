@@ -31,6 +31,13 @@ const (
 
 	// ManifestTypeSpriteSheet is a sprite sheet resource.
 	ManifestTypeSpriteSheet ResourceType = "spritesheet"
+
+	// ManifestTypeRoom is a room resource.
+	ManifestTypeRoom ResourceType = "room"
+
+	// ManifestTypeRoomGeometry is a room geometry resource. It has no manifest, since it is
+	// imported from Tiled maps.
+	ManifestTypeRoomGeometry ResourceType = "roomgeometry"
 )
 
 // Manifest is the description of a resource.
@@ -75,6 +82,8 @@ func (m *Manifest) UnmarshalYAML(n *yaml.Node) error {
 		m.Data = NewMusicData(m.workingDir)
 	case ManifestTypeImage:
 		m.Data = NewImageData(m.workingDir)
+	case ManifestTypeRoom:
+		m.Data = NewRoomData()
 	case ManifestTypeScript:
 		m.Data = new(ScriptData)
 	case ManifestTypeSound:
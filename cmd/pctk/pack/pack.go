@@ -1,95 +1,321 @@
 package pack
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/apoloval/pctk"
-	rl "github.com/gen2brain/raylib-go/raylib"
 )
 
-func do(src string, output string) error {
-	rl.SetTraceLogLevel(rl.LogNone)
+func do(src string, output string, force bool) error {
+	prev := NewCache()
+	if !force {
+		prev = LoadCache(output)
+	}
 
 	idxFile, datFile, err := createOutputFiles(output)
 	if err != nil {
 		return err
 	}
+	defer os.Remove(idxFile.Name())
+	defer os.Remove(datFile.Name())
 	defer idxFile.Close()
 	defer datFile.Close()
 
-	enc, err := pctk.NewResourceEncoder(idxFile, datFile)
+	next, err := encodeAll(src, idxFile, datFile, prev)
 	if err != nil {
 		return err
 	}
 
-	manifests, err := listManifests(src)
+	if err := idxFile.Close(); err != nil {
+		return err
+	}
+	if err := datFile.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(idxFile.Name(), output+".idx"); err != nil {
+		return err
+	}
+	if err := os.Rename(datFile.Name(), output+".dat"); err != nil {
+		return err
+	}
+	return next.Save(output)
+}
+
+// verify packs the resources in src from scratch and compares the result with the existing output
+// files, reporting the resources that differ.
+func verify(src string, output string) error {
+	dir, err := os.MkdirTemp("", "pctk-verify")
 	if err != nil {
 		return err
 	}
-	for _, manifest := range manifests {
-		id := pctk.ResourceID(strings.TrimSuffix(manifest, filepath.Ext(manifest)))
-		fmt.Printf("Packing %s...", id)
-		man, err := LoadManifestFromFile(filepath.Join(src, manifest))
+	defer os.RemoveAll(dir)
+
+	const pkg = pctk.ResourcePackage("verify")
+	idxFile, datFile, err := createOutputFiles(filepath.Join(dir, pkg.String()))
+	if err != nil {
+		return err
+	}
+	defer idxFile.Close()
+	defer datFile.Close()
+	if _, err := encodeAll(src, idxFile, datFile, NewCache()); err != nil {
+		return err
+	}
+	if err := idxFile.Close(); err != nil {
+		return err
+	}
+	if err := datFile.Close(); err != nil {
+		return err
+	}
+	for _, ext := range []string{".idx", ".dat"} {
+		tmp := filepath.Join(dir, pkg.String()+ext)
+		if err := os.Rename(tmp+".tmp", tmp); err != nil {
+			return err
+		}
+	}
+
+	diffs, err := comparePackages(
+		pctk.NewResourceFileLoader(filepath.Dir(output)), pctk.ResourcePackage(filepath.Base(output)),
+		pctk.NewResourceFileLoader(dir), pkg,
+	)
+	if err != nil {
+		return err
+	}
+	if len(diffs) == 0 {
+		// Resources match, but the index might still list them in a different order.
+		equal, err := sameFiles(output+".idx", filepath.Join(dir, pkg.String()+".idx"))
 		if err != nil {
 			return err
 		}
+		if !equal {
+			diffs = append(diffs, "Index files differ")
+		}
+	}
+	for _, diff := range diffs {
+		fmt.Fprintln(os.Stderr, diff)
+	}
+	if len(diffs) > 0 {
+		return fmt.Errorf("package %s does not match its sources in %s", output, src)
+	}
+	fmt.Printf("Package %s matches its sources in %s\n", output, src)
+	return nil
+}
+
+// comparePackages returns a description of each resource that differs between two packages.
+func comparePackages(
+	oldLoader *pctk.ResourceFileLoader, oldPkg pctk.ResourcePackage,
+	newLoader *pctk.ResourceFileLoader, newPkg pctk.ResourcePackage,
+) ([]string, error) {
+	oldRes, err := oldLoader.ListResources(oldPkg)
+	if err != nil {
+		return nil, err
+	}
+	newRes, err := newLoader.ListResources(newPkg)
+	if err != nil {
+		return nil, err
+	}
 
-		switch data := man.Data.(type) {
-		case *CostumeData:
-			err = enc.EncodeCostume(id, data.Resource, man.Compression)
-		case *ImageData:
-			err = enc.EncodeImage(id, data.Resource, man.Compression)
-		case *MusicData:
-			err = enc.EncodeMusic(id, data.Resource, man.Compression)
-		case *ScriptData:
-			err = enc.EncodeScript(id, data.Resource, man.Compression)
-		case *SoundData:
-			err = enc.EncodeSound(id, data.Resource, man.Compression)
-		case *SpriteSheetData:
-			err = enc.EncodeSpriteSheet(id, data.Resource, man.Compression)
+	var diffs []string
+	for _, id := range sortedIDs(oldRes) {
+		if _, ok := newRes[id]; !ok {
+			diffs = append(diffs, fmt.Sprintf("Resource %s is not in the sources", id))
 		}
+	}
+	for _, id := range sortedIDs(newRes) {
+		if _, ok := oldRes[id]; !ok {
+			diffs = append(diffs, fmt.Sprintf("Resource %s is not in the package", id))
+			continue
+		}
+		oldData, err := oldLoader.LoadRaw(pctk.NewResourceRef(oldPkg, id))
 		if err != nil {
-			fmt.Printf(" Failed!\n")
-			return err
+			return nil, err
+		}
+		newData, err := newLoader.LoadRaw(pctk.NewResourceRef(newPkg, id))
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(oldData, newData) {
+			diffs = append(diffs, fmt.Sprintf("Resource %s differs", id))
+		}
+	}
+	return diffs, nil
+}
+
+func sortedIDs(resources map[pctk.ResourceID]string) []pctk.ResourceID {
+	ids := make([]pctk.ResourceID, 0, len(resources))
+	for id := range resources {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+func sameFiles(a, b string) (bool, error) {
+	dataA, err := os.ReadFile(a)
+	if err != nil {
+		return false, err
+	}
+	dataB, err := os.ReadFile(b)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(dataA, dataB), nil
+}
+
+// encodeAll encodes the resources in src into the given index and data writers. Resources are
+// encoded in a stable order, so the same sources always produce the same output. It returns the
+// cache for the written output.
+func encodeAll(src string, idx, dat io.Writer, prev *Cache) (*Cache, error) {
+	enc, err := pctk.NewResourceEncoder(idx, dat)
+	if err != nil {
+		return nil, err
+	}
+	p := &packer{enc: enc, prev: prev, next: NewCache()}
+
+	manifests, err := listManifests(src)
+	if err != nil {
+		return nil, err
+	}
+	for _, manifest := range manifests {
+		id := resourceID(manifest)
+		path := filepath.Join(src, manifest)
+		sources, err := manifestSources(path)
+		if err != nil {
+			return nil, err
+		}
+		err = p.pack(id, sources, func() error {
+			man, err := LoadManifestFromFile(path)
+			if err != nil {
+				return err
+			}
+			switch data := man.Data.(type) {
+			case *CostumeData:
+				return enc.EncodeCostume(id, data.Resource, man.Compression)
+			case *ImageData:
+				return enc.EncodeImage(id, data.Resource, man.Compression)
+			case *MusicData:
+				return enc.EncodeMusic(id, data.Resource, man.Compression)
+			case *RoomData:
+				return enc.EncodeRoom(id, data.Resource, man.Compression)
+			case *ScriptData:
+				return enc.EncodeScript(id, data.Resource, man.Compression)
+			case *SoundData:
+				return enc.EncodeSound(id, data.Resource, man.Compression)
+			case *SpriteSheetData:
+				return enc.EncodeSpriteSheet(id, data.Resource, man.Compression)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
 		}
-		fmt.Printf(" Done\n")
 	}
 
 	luaScripts, err := listLuaScripts(src)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	for _, script := range luaScripts {
-		id := pctk.ResourceID(strings.TrimSuffix(script, filepath.Ext(script)))
-		fmt.Printf("Packing %s...", id)
-		code, err := os.ReadFile(filepath.Join(src, script))
+		id := resourceID(script)
+		path := filepath.Join(src, script)
+		err = p.pack(id, []string{path}, func() error {
+			code, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			script := pctk.NewScript(pctk.ScriptLua, code)
+			return enc.EncodeScript(id, script, pctk.CompressionNone)
+		})
 		if err != nil {
-			return err
+			return nil, err
 		}
+	}
 
-		script := pctk.NewScript(pctk.ScriptLua, code)
-		err = enc.EncodeScript(id, script, pctk.CompressionNone)
+	tiledMaps, err := listTiledMaps(src)
+	if err != nil {
+		return nil, err
+	}
+	for _, tiledMap := range tiledMaps {
+		id := resourceID(tiledMap)
+		path := filepath.Join(src, tiledMap)
+		sources, err := tiledSources(path)
 		if err != nil {
-			fmt.Printf(" Failed!\n")
-			return err
+			return nil, err
+		}
+		err = p.pack(id, sources, func() error {
+			m, err := LoadTiledMap(path)
+			if err != nil {
+				return err
+			}
+			g, err := m.Geometry()
+			if err != nil {
+				return fmt.Errorf("error importing %s: %w", path, err)
+			}
+			return enc.EncodeRoomGeometry(id, g, pctk.CompressionNone)
+		})
+		if err != nil {
+			return nil, err
 		}
-		fmt.Printf(" Done\n")
 	}
 
-	fmt.Printf("%d data bytes written\n", enc.DataBytesWritten())
+	fmt.Printf("%d data bytes written, %d resources reused\n", enc.DataBytesWritten(), p.reused)
+	return p.next, nil
+}
+
+// resourceID returns the ID of the resource in the given file, relative to the source directory.
+// IDs always use slashes as separator regardless of the platform.
+func resourceID(file string) pctk.ResourceID {
+	return pctk.ResourceID(filepath.ToSlash(strings.TrimSuffix(file, filepath.Ext(file))))
+}
+
+// packer encodes resources, reusing the ones of a previous output whose sources did not change.
+type packer struct {
+	enc    *pctk.ResourceEncoder
+	prev   *Cache
+	next   *Cache
+	reused int
+}
+
+func (p *packer) pack(id pctk.ResourceID, sources []string, encode func() error) error {
+	fmt.Printf("Packing %s...", id)
+	hash, err := hashFiles(sources...)
+	if err != nil {
+		fmt.Printf(" Failed!\n")
+		return err
+	}
+
+	status := "Done"
+	if data, ok := p.prev.Lookup(id, hash); ok {
+		err = p.enc.EncodeRaw(id, data)
+		status = "Unchanged"
+		p.reused++
+	} else {
+		err = encode()
+	}
+	if err != nil {
+		fmt.Printf(" Failed!\n")
+		return err
+	}
+	p.next.Hashes[id] = hash
+	fmt.Printf(" %s\n", status)
 	return nil
 }
 
+// createOutputFiles creates temporary output files that are renamed to the final ones once the
+// packing succeeds. This preserves the previous output, which is read to reuse cached resources.
 func createOutputFiles(output string) (*os.File, *os.File, error) {
-	idx, err := os.Create(output + ".idx")
+	idx, err := os.Create(output + ".idx.tmp")
 	if err != nil {
 		return nil, nil, err
 	}
-	dat, err := os.Create(output + ".dat")
+	dat, err := os.Create(output + ".dat.tmp")
 	if err != nil {
+		idx.Close()
 		return nil, nil, err
 	}
 	return idx, dat, nil
@@ -103,6 +329,10 @@ func listLuaScripts(dir string) ([]string, error) {
 	return listFiles(dir, ".lua")
 }
 
+func listTiledMaps(dir string) ([]string, error) {
+	return listFiles(dir, ".tmx", ".tmj")
+}
+
 func listFiles(dir string, extensions ...string) ([]string, error) {
 	entries, err := os.ReadDir(dir)
 	if err != nil {
@@ -132,5 +362,8 @@ func listFiles(dir string, extensions ...string) ([]string, error) {
 			}
 		}
 	}
+
+	// Do not rely on the order of directory entries, so packages are reproducible.
+	slices.Sort(files)
 	return files, nil
 }
@@ -0,0 +1,280 @@
+package pack
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/apoloval/pctk"
+	"gopkg.in/yaml.v3"
+)
+
+// RoomData is the data for a room resource.
+type RoomData struct {
+	Resource *pctk.RoomDescription
+}
+
+// NewRoomData creates a new room data.
+func NewRoomData() *RoomData {
+	return &RoomData{}
+}
+
+type posData struct {
+	X, Y int
+}
+
+func (p posData) pos() pctk.Position {
+	return pctk.NewPos(p.X, p.Y)
+}
+
+// animationData is an animation as described in manifests. Events are attached to frames by their
+// index in the animation, counting from zero.
+type animationData struct {
+	Flip   bool
+	Frames []struct {
+		Row      int
+		Columns  []int
+		Duration int
+	}
+	Events []struct {
+		Frame   int
+		Event   string
+		Sound   string
+		Connect bool
+	}
+}
+
+func (d animationData) animation() (*pctk.Animation, error) {
+	anim := pctk.NewAnimation().Flip(d.Flip)
+	for _, frame := range d.Frames {
+		anim.AddFrames(time.Duration(frame.Duration)*time.Millisecond, frame.Row, frame.Columns...)
+	}
+	for _, ev := range d.Events {
+		if ev.Frame < 0 || ev.Frame >= anim.Frames() {
+			return nil, fmt.Errorf("invalid frame %d for event, animation has %d frames",
+				ev.Frame, anim.Frames())
+		}
+		e := pctk.AnimationEvent{Name: ev.Event, Connect: ev.Connect}
+		if ev.Sound != "" {
+			ref, err := pctk.ParseResourceRef(ev.Sound)
+			if err != nil {
+				return nil, err
+			}
+			e.Sound = ref
+		}
+		anim.AddEvent(ev.Frame, e)
+	}
+	return anim, nil
+}
+
+var objectClasses = map[string]pctk.ObjectClass{
+	"person":      pctk.ObjectClassPerson,
+	"untouchable": pctk.ObjectClassUntouchable,
+	"pickable":    pctk.ObjectClassPickable,
+	"openable":    pctk.ObjectClassOpenable,
+	"closeable":   pctk.ObjectClassCloseable,
+	"applicable":  pctk.ObjectClassApplicable,
+}
+
+func (d *RoomData) UnmarshalYAML(n *yaml.Node) error {
+	var data struct {
+		Background string
+		Zoom       *float32
+		Layers     []struct {
+			Image    string
+			Pos      posData
+			Baseline int
+		}
+		Parallax []struct {
+			Image  string
+			Pos    posData
+			Factor *float32
+			Scroll float32
+			Front  bool
+		}
+		WalkBoxes []struct {
+			ID       string
+			Vertices []posData
+			Scale    *float32
+			FarScale float32
+			Mask     int
+			Disabled bool
+		}
+		Objects []struct {
+			ID      string
+			Name    string
+			Class   []string
+			Hotspot struct {
+				X, Y, W, H int
+			}
+			Pos     posData
+			UsePos  posData
+			UseDir  string
+			Sprites string
+			State   string
+			States  map[string]*animationData
+		}
+		Actors []struct {
+			ID        string
+			Name      string
+			Costume   string
+			Pos       *posData
+			Dir       string
+			Size      *struct{ W, H int }
+			TalkColor *struct{ R, G, B uint8 }
+			UsePos    *posData
+			UseDir    string
+		}
+	}
+	if err := n.Decode(&data); err != nil {
+		return err
+	}
+
+	room := &pctk.RoomDescription{Zoom: 1}
+	var err error
+	if room.Background, err = pctk.ParseResourceRef(data.Background); err != nil {
+		return fmt.Errorf("invalid background: %w", err)
+	}
+	if data.Zoom != nil {
+		if *data.Zoom <= 0 {
+			return fmt.Errorf("invalid zoom %v, it must be positive", *data.Zoom)
+		}
+		room.Zoom = *data.Zoom
+	}
+
+	for i, layer := range data.Layers {
+		desc := pctk.LayerDescription{Pos: layer.Pos.pos(), Baseline: layer.Baseline}
+		if desc.Image, err = pctk.ParseResourceRef(layer.Image); err != nil {
+			return fmt.Errorf("layer %d: invalid image: %w", i+1, err)
+		}
+		room.Layers = append(room.Layers, desc)
+	}
+
+	for i, layer := range data.Parallax {
+		desc := pctk.ParallaxDescription{Pos: layer.Pos.pos(), Factor: 1, Scroll: layer.Scroll, Front: layer.Front}
+		if layer.Factor != nil {
+			desc.Factor = *layer.Factor
+		}
+		if desc.Image, err = pctk.ParseResourceRef(layer.Image); err != nil {
+			return fmt.Errorf("parallax layer %d: invalid image: %w", i+1, err)
+		}
+		room.Parallax = append(room.Parallax, desc)
+	}
+
+	for _, wb := range data.WalkBoxes {
+		g := pctk.WalkBoxGeometry{ID: wb.ID, Scale: 1, FarScale: wb.FarScale, Mask: wb.Mask, Enabled: !wb.Disabled}
+		if wb.Scale != nil {
+			g.Scale = *wb.Scale
+		}
+		if len(wb.Vertices) < 3 {
+			return fmt.Errorf("walkbox %s has %d vertices, at least 3 are required", wb.ID, len(wb.Vertices))
+		}
+		if wb.Mask < 0 || wb.Mask > len(room.Layers) {
+			return fmt.Errorf("walkbox %s has mask %d, but the room has %d layers", wb.ID, wb.Mask, len(room.Layers))
+		}
+		for _, v := range wb.Vertices {
+			g.Vertices = append(g.Vertices, v.pos())
+		}
+		if !pctk.IsConvexPolygon(g.Vertices) {
+			return fmt.Errorf("walkbox %s must be a convex polygon: %v", wb.ID, g.Vertices)
+		}
+		room.WalkBoxes = append(room.WalkBoxes, g)
+	}
+
+	for _, obj := range data.Objects {
+		desc := pctk.ObjectDescription{
+			ID:      obj.ID,
+			Name:    obj.Name,
+			Hotspot: pctk.NewRect(obj.Hotspot.X, obj.Hotspot.Y, obj.Hotspot.W, obj.Hotspot.H),
+			Pos:     obj.Pos.pos(),
+			UsePos:  obj.UsePos.pos(),
+			State:   obj.State,
+		}
+		if desc.Name == "" {
+			desc.Name = obj.ID
+		}
+		for _, class := range obj.Class {
+			c, ok := objectClasses[strings.ToLower(class)]
+			if !ok {
+				return fmt.Errorf("object %s: unknown class %q", obj.ID, class)
+			}
+			desc.Class = desc.Class.Enable(c)
+		}
+		if obj.UseDir != "" {
+			if desc.UseDir, err = pctk.ParseDirection(obj.UseDir); err != nil {
+				return fmt.Errorf("object %s: %w", obj.ID, err)
+			}
+		}
+		if obj.Sprites != "" {
+			if desc.Sprites, err = pctk.ParseResourceRef(obj.Sprites); err != nil {
+				return fmt.Errorf("object %s: %w", obj.ID, err)
+			}
+		}
+		names := make([]string, 0, len(obj.States))
+		for name := range obj.States {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+		for _, name := range names {
+			state := pctk.ObjectStateDescription{Name: name}
+			if anim := obj.States[name]; anim != nil {
+				if state.Anim, err = anim.animation(); err != nil {
+					return fmt.Errorf("object %s: state %s: %w", obj.ID, name, err)
+				}
+			}
+			desc.States = append(desc.States, state)
+		}
+		if _, ok := obj.States[desc.State]; desc.State != "" && !ok {
+			return fmt.Errorf("object %s: unknown state %q", obj.ID, desc.State)
+		}
+		room.Objects = append(room.Objects, desc)
+	}
+
+	for _, actor := range data.Actors {
+		desc := pctk.ActorDescription{
+			ID:        actor.ID,
+			Name:      actor.Name,
+			Pos:       pctk.DefaultActorPosition,
+			Dir:       pctk.DefaultActorDirection,
+			Size:      pctk.DefaultActorSize,
+			TalkColor: pctk.DefaultActorTalkColor,
+			UsePos:    pctk.DefaultActorUsePos,
+			UseDir:    pctk.DefaultActorDirection,
+		}
+		if desc.Name == "" {
+			desc.Name = actor.ID
+		}
+		if actor.Costume != "" {
+			if desc.Costume, err = pctk.ParseResourceRef(actor.Costume); err != nil {
+				return fmt.Errorf("actor %s: %w", actor.ID, err)
+			}
+		}
+		if actor.Pos != nil {
+			desc.Pos = actor.Pos.pos()
+		}
+		if actor.Dir != "" {
+			if desc.Dir, err = pctk.ParseDirection(actor.Dir); err != nil {
+				return fmt.Errorf("actor %s: %w", actor.ID, err)
+			}
+		}
+		if actor.Size != nil {
+			desc.Size = pctk.NewSize(actor.Size.W, actor.Size.H)
+		}
+		if c := actor.TalkColor; c != nil {
+			desc.TalkColor = pctk.Color{R: c.R, G: c.G, B: c.B, A: 255}
+		}
+		if actor.UsePos != nil {
+			desc.UsePos = actor.UsePos.pos()
+		}
+		if actor.UseDir != "" {
+			if desc.UseDir, err = pctk.ParseDirection(actor.UseDir); err != nil {
+				return fmt.Errorf("actor %s: %w", actor.ID, err)
+			}
+		}
+		room.Actors = append(room.Actors, desc)
+	}
+
+	d.Resource = room
+	return nil
+}
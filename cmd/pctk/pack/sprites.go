@@ -23,15 +23,33 @@ func (d *SpriteSheetData) UnmarshalYAML(n *yaml.Node) error {
 			Width  uint
 			Height uint
 		}
-		Source string
+		Source   string
+		Atlas    []AtlasRow
+		Aseprite string
 	}
 	if err := n.Decode(&data); err != nil {
 		return err
 	}
 
-	d.Resource = pctk.LoadSpriteSheetFromFile(
-		filepath.Join(d.workingDir, data.Source),
-		pctk.Size{W: int(data.Frames.Width), H: int(data.Frames.Height)},
-	)
+	if data.Aseprite != "" {
+		sheet, err := LoadAseprite(filepath.Join(d.workingDir, data.Aseprite))
+		if err != nil {
+			return err
+		}
+		d.Resource, err = sheet.SpriteSheet()
+		return err
+	}
+
+	frameSize := pctk.Size{W: int(data.Frames.Width), H: int(data.Frames.Height)}
+	if len(data.Atlas) > 0 {
+		sheet, err := LoadAtlas(d.workingDir, frameSize, data.Atlas)
+		if err != nil {
+			return err
+		}
+		d.Resource = sheet
+		return nil
+	}
+
+	d.Resource = pctk.LoadSpriteSheetFromFile(filepath.Join(d.workingDir, data.Source), frameSize)
 	return nil
 }
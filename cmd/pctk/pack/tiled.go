@@ -0,0 +1,443 @@
+package pack
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/apoloval/pctk"
+)
+
+// TiledMap is a map designed with the Tiled map editor, flattened to the layers that describe the
+// geometry of a room. Object positions include the offsets of their layers.
+type TiledMap struct {
+	Images  []TiledImage
+	Objects []TiledObject
+}
+
+// TiledImage is an image layer of a Tiled map.
+type TiledImage struct {
+	Path       string
+	X, Y       float64
+	ParallaxX  float64
+	Properties map[string]string
+}
+
+// TiledObject is an object of a Tiled map.
+type TiledObject struct {
+	ID         int
+	Name       string
+	X, Y, W, H float64
+	Polygon    [][2]float64
+	Point      bool
+	Shape      string // Unsupported shapes, like ellipses or polylines.
+	Properties map[string]string
+}
+
+// LoadTiledMap loads a Tiled map in TMX (XML) or TMJ (JSON) format. Tilesets and tile layers are
+// ignored.
+func LoadTiledMap(path string) (*TiledMap, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	m := new(TiledMap)
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".tmx":
+		var doc tmxGroup
+		if err := xml.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("error decoding %s: %w", path, err)
+		}
+		err = m.addTMXGroup(filepath.Dir(path), doc, 0, 0)
+	case ".tmj":
+		var doc tmjLayer
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("error decoding %s: %w", path, err)
+		}
+		err = m.addTMJLayers(filepath.Dir(path), doc.Layers, 0, 0)
+	default:
+		return nil, fmt.Errorf("unknown Tiled map format: %s", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error loading %s: %w", path, err)
+	}
+	return m, nil
+}
+
+// Geometry returns the room geometry described by the map. Image layers with a horizontal parallax
+// factor other than 1 or a scroll property are parallax layers, drawn behind the room plane if they
+// come before the background or in front of it otherwise. Of the rest of image layers, the first
+// one is the background and the following ones are foreground layers. Polygons are walkboxes,
+// rectangles are object hotspots and points are entrances. These custom properties are supported:
+//   - parallax layers: scroll (float), the speed in pixels per second they scroll by themselves.
+//   - foreground layers: baseline (int), the Y over which actors are drawn behind the layer.
+//   - walkboxes: scale (float), farscale (float), the scale at their top edge, mask (int), the
+//     index of the first foreground layer that covers actors in the walkbox, and enabled (bool).
+//   - hotspots: usepos (object), a point where actors use the object, and usedir (string).
+func (m *TiledMap) Geometry() (*pctk.RoomGeometry, error) {
+	g := new(pctk.RoomGeometry)
+	background := false
+	for _, img := range m.Images {
+		if scroll, ok := img.Properties["scroll"]; ok || img.ParallaxX != 1 {
+			layer := pctk.ParallaxGeometry{
+				Pos:    roundPos(img.X, img.Y),
+				Factor: float32(img.ParallaxX),
+				Front:  background,
+			}
+			if ok {
+				speed, err := strconv.ParseFloat(scroll, 32)
+				if err != nil {
+					return nil, fmt.Errorf("image layer %s: invalid scroll %q", img.Path, scroll)
+				}
+				layer.Scroll = float32(speed)
+			}
+			layer.Image = pctk.LoadImageFromFile(img.Path)
+			g.Parallax = append(g.Parallax, layer)
+			continue
+		}
+		if !background {
+			background = true
+			if img.X != 0 || img.Y != 0 {
+				return nil, fmt.Errorf("background image %s: offsets are not supported", img.Path)
+			}
+			g.Background = pctk.LoadImageFromFile(img.Path)
+			continue
+		}
+		layer := pctk.LayerGeometry{Pos: roundPos(img.X, img.Y)}
+		if v, ok := img.Properties["baseline"]; ok {
+			baseline, err := strconv.Atoi(v)
+			if err != nil {
+				return nil, fmt.Errorf("image layer %s: invalid baseline %q", img.Path, v)
+			}
+			layer.Baseline = baseline
+		}
+		layer.Image = pctk.LoadImageFromFile(img.Path)
+		g.Layers = append(g.Layers, layer)
+	}
+
+	byID := make(map[int]TiledObject)
+	for _, obj := range m.Objects {
+		byID[obj.ID] = obj
+	}
+	usePoints := make(map[int]bool)
+
+	for _, obj := range m.Objects {
+		switch {
+		case obj.Shape != "":
+			return nil, fmt.Errorf("object %d: unsupported %s shape", obj.ID, obj.Shape)
+		case obj.Polygon != nil:
+			wb := pctk.WalkBoxGeometry{ID: obj.Name, Scale: 1, Enabled: true}
+			if wb.ID == "" {
+				wb.ID = fmt.Sprintf("walkbox%d", obj.ID)
+			}
+			for _, p := range obj.Polygon {
+				wb.Vertices = append(wb.Vertices, roundPos(obj.X+p[0], obj.Y+p[1]))
+			}
+			if len(wb.Vertices) < 3 {
+				return nil, fmt.Errorf("walkbox %s must have at least 3 vertices", wb.ID)
+			}
+			if !pctk.IsConvexPolygon(wb.Vertices) {
+				return nil, fmt.Errorf("walkbox %s must be a convex polygon: %v", wb.ID, wb.Vertices)
+			}
+			if v, ok := obj.Properties["scale"]; ok {
+				scale, err := strconv.ParseFloat(v, 32)
+				if err != nil {
+					return nil, fmt.Errorf("walkbox %s: invalid scale %q", wb.ID, v)
+				}
+				wb.Scale = float32(scale)
+			}
+			if v, ok := obj.Properties["farscale"]; ok {
+				scale, err := strconv.ParseFloat(v, 32)
+				if err != nil {
+					return nil, fmt.Errorf("walkbox %s: invalid farscale %q", wb.ID, v)
+				}
+				wb.FarScale = float32(scale)
+			}
+			if v, ok := obj.Properties["mask"]; ok {
+				mask, err := strconv.Atoi(v)
+				if err != nil || mask < 0 || mask > len(g.Layers) {
+					return nil, fmt.Errorf("walkbox %s: invalid mask %q", wb.ID, v)
+				}
+				wb.Mask = mask
+			}
+			if v, ok := obj.Properties["enabled"]; ok {
+				enabled, err := strconv.ParseBool(v)
+				if err != nil {
+					return nil, fmt.Errorf("walkbox %s: invalid enabled %q", wb.ID, v)
+				}
+				wb.Enabled = enabled
+			}
+			g.WalkBoxes = append(g.WalkBoxes, wb)
+		case !obj.Point && obj.W > 0 && obj.H > 0:
+			if obj.Name == "" {
+				return nil, fmt.Errorf("object %d: hotspots must be named after their object", obj.ID)
+			}
+			h := pctk.HotspotGeometry{
+				ID: obj.Name,
+				Rect: pctk.Rectangle{
+					Pos:  roundPos(obj.X, obj.Y),
+					Size: pctk.NewSize(int(math.Round(obj.W)), int(math.Round(obj.H))),
+				},
+			}
+			if v, ok := obj.Properties["usepos"]; ok {
+				id, _ := strconv.Atoi(v)
+				point, ok := byID[id]
+				if !ok {
+					return nil, fmt.Errorf("hotspot %s: usepos refers to unknown object %q", h.ID, v)
+				}
+				h.UsePos, h.HasUsePos = roundPos(point.X, point.Y), true
+				usePoints[id] = true
+			}
+			if v, ok := obj.Properties["usedir"]; ok {
+				dir, err := pctk.ParseDirection(v)
+				if err != nil {
+					return nil, fmt.Errorf("hotspot %s: %w", h.ID, err)
+				}
+				h.UseDir, h.HasUseDir = dir, true
+			}
+			g.Hotspots = append(g.Hotspots, h)
+		}
+	}
+
+	for _, obj := range m.Objects {
+		isPoint := obj.Point || (obj.Shape == "" && obj.Polygon == nil && (obj.W == 0 || obj.H == 0))
+		if !isPoint || usePoints[obj.ID] {
+			continue
+		}
+		if obj.Name == "" {
+			return nil, fmt.Errorf("object %d: entrances must be named", obj.ID)
+		}
+		g.Entrances = append(g.Entrances, pctk.EntranceGeometry{
+			ID:  obj.Name,
+			Pos: roundPos(obj.X, obj.Y),
+		})
+	}
+	return g, nil
+}
+
+// tiledSources returns the files a Tiled map depends on.
+func tiledSources(path string) ([]string, error) {
+	m, err := LoadTiledMap(path)
+	if err != nil {
+		return nil, err
+	}
+	sources := []string{path}
+	for _, img := range m.Images {
+		sources = append(sources, img.Path)
+	}
+	return sources, nil
+}
+
+type tmxGroup struct {
+	OffsetX      float64          `xml:"offsetx,attr"`
+	OffsetY      float64          `xml:"offsety,attr"`
+	ImageLayers  []tmxImageLayer  `xml:"imagelayer"`
+	ObjectGroups []tmxObjectGroup `xml:"objectgroup"`
+	Groups       []tmxGroup       `xml:"group"`
+}
+
+type tmxImageLayer struct {
+	OffsetX    float64       `xml:"offsetx,attr"`
+	OffsetY    float64       `xml:"offsety,attr"`
+	ParallaxX  *float64      `xml:"parallaxx,attr"`
+	Visible    string        `xml:"visible,attr"`
+	Properties []tmxProperty `xml:"properties>property"`
+	Image      struct {
+		Source string `xml:"source,attr"`
+	} `xml:"image"`
+}
+
+type tmxProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+	Text  string `xml:",chardata"`
+}
+
+func tmxProperties(props []tmxProperty) map[string]string {
+	m := make(map[string]string)
+	for _, p := range props {
+		m[p.Name] = p.Value
+		if p.Value == "" {
+			m[p.Name] = p.Text
+		}
+	}
+	return m
+}
+
+type tmxObjectGroup struct {
+	OffsetX float64     `xml:"offsetx,attr"`
+	OffsetY float64     `xml:"offsety,attr"`
+	Objects []tmxObject `xml:"object"`
+}
+
+type tmxObject struct {
+	ID         int           `xml:"id,attr"`
+	Name       string        `xml:"name,attr"`
+	X          float64       `xml:"x,attr"`
+	Y          float64       `xml:"y,attr"`
+	Width      float64       `xml:"width,attr"`
+	Height     float64       `xml:"height,attr"`
+	Properties []tmxProperty `xml:"properties>property"`
+	Polygon    *struct {
+		Points string `xml:"points,attr"`
+	} `xml:"polygon"`
+	Point    *struct{} `xml:"point"`
+	Ellipse  *struct{} `xml:"ellipse"`
+	Polyline *struct{} `xml:"polyline"`
+}
+
+func (m *TiledMap) addTMXGroup(dir string, g tmxGroup, dx, dy float64) error {
+	dx, dy = dx+g.OffsetX, dy+g.OffsetY
+	for _, layer := range g.ImageLayers {
+		if layer.Visible == "0" || layer.Image.Source == "" {
+			continue
+		}
+		m.addImage(dir, layer.Image.Source, dx+layer.OffsetX, dy+layer.OffsetY, layer.ParallaxX,
+			tmxProperties(layer.Properties))
+	}
+	for _, group := range g.ObjectGroups {
+		for _, o := range group.Objects {
+			obj := TiledObject{
+				ID:         o.ID,
+				Name:       o.Name,
+				X:          o.X + dx + group.OffsetX,
+				Y:          o.Y + dy + group.OffsetY,
+				W:          o.Width,
+				H:          o.Height,
+				Point:      o.Point != nil,
+				Properties: tmxProperties(o.Properties),
+			}
+			switch {
+			case o.Ellipse != nil:
+				obj.Shape = "ellipse"
+			case o.Polyline != nil:
+				obj.Shape = "polyline"
+			case o.Polygon != nil:
+				for _, pair := range strings.Fields(o.Polygon.Points) {
+					var x, y float64
+					if _, err := fmt.Sscanf(pair, "%g,%g", &x, &y); err != nil {
+						return fmt.Errorf("object %d: invalid polygon point %q", o.ID, pair)
+					}
+					obj.Polygon = append(obj.Polygon, [2]float64{x, y})
+				}
+			}
+			m.Objects = append(m.Objects, obj)
+		}
+	}
+	for _, group := range g.Groups {
+		if err := m.addTMXGroup(dir, group, dx, dy); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type tmjLayer struct {
+	Type       string        `json:"type"`
+	Visible    *bool         `json:"visible"`
+	OffsetX    float64       `json:"offsetx"`
+	OffsetY    float64       `json:"offsety"`
+	ParallaxX  *float64      `json:"parallaxx"`
+	Image      string        `json:"image"`
+	Properties []tmjProperty `json:"properties"`
+	Objects    []tmjObject   `json:"objects"`
+	Layers     []tmjLayer    `json:"layers"`
+}
+
+type tmjProperty struct {
+	Name  string `json:"name"`
+	Value any    `json:"value"`
+}
+
+func tmjProperties(props []tmjProperty) map[string]string {
+	m := make(map[string]string)
+	for _, p := range props {
+		m[p.Name] = fmt.Sprint(p.Value)
+	}
+	return m
+}
+
+type tmjObject struct {
+	ID         int           `json:"id"`
+	Name       string        `json:"name"`
+	X          float64       `json:"x"`
+	Y          float64       `json:"y"`
+	Width      float64       `json:"width"`
+	Height     float64       `json:"height"`
+	Point      bool          `json:"point"`
+	Ellipse    bool          `json:"ellipse"`
+	Properties []tmjProperty `json:"properties"`
+	Polygon    []struct {
+		X float64 `json:"x"`
+		Y float64 `json:"y"`
+	} `json:"polygon"`
+	Polyline []any `json:"polyline"`
+}
+
+func (m *TiledMap) addTMJLayers(dir string, layers []tmjLayer, dx, dy float64) error {
+	for _, layer := range layers {
+		if layer.Visible != nil && !*layer.Visible {
+			continue
+		}
+		lx, ly := dx+layer.OffsetX, dy+layer.OffsetY
+		switch layer.Type {
+		case "imagelayer":
+			if layer.Image == "" {
+				continue
+			}
+			m.addImage(dir, layer.Image, lx, ly, layer.ParallaxX, tmjProperties(layer.Properties))
+		case "objectgroup":
+			for _, o := range layer.Objects {
+				obj := TiledObject{
+					ID:         o.ID,
+					Name:       o.Name,
+					X:          o.X + lx,
+					Y:          o.Y + ly,
+					W:          o.Width,
+					H:          o.Height,
+					Point:      o.Point,
+					Properties: tmjProperties(o.Properties),
+				}
+				switch {
+				case o.Ellipse:
+					obj.Shape = "ellipse"
+				case o.Polyline != nil:
+					obj.Shape = "polyline"
+				case o.Polygon != nil:
+					for _, p := range o.Polygon {
+						obj.Polygon = append(obj.Polygon, [2]float64{p.X, p.Y})
+					}
+				}
+				m.Objects = append(m.Objects, obj)
+			}
+		case "group":
+			if err := m.addTMJLayers(dir, layer.Layers, lx, ly); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (m *TiledMap) addImage(dir, source string, dx, dy float64, parallaxX *float64, props map[string]string) {
+	img := TiledImage{
+		Path:       filepath.Join(dir, source),
+		X:          dx,
+		Y:          dy,
+		ParallaxX:  1,
+		Properties: props,
+	}
+	if parallaxX != nil {
+		img.ParallaxX = *parallaxX
+	}
+	m.Images = append(m.Images, img)
+}
+
+func roundPos(x, y float64) pctk.Position {
+	return pctk.NewPos(int(math.Round(x)), int(math.Round(y)))
+}
@@ -0,0 +1,269 @@
+package pack
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/apoloval/pctk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tiledFixtures are the same map in both Tiled formats. The room group and its object layer have
+// offsets, and so does the group nested in it.
+var tiledFixtures = map[string]string{
+	"hall.tmx": `<?xml version="1.0" encoding="UTF-8"?>
+<map version="1.10" orientation="orthogonal" width="10" height="5" tilewidth="32" tileheight="32">
+ <imagelayer id="1" name="background">
+  <image source="hall.png" width="320" height="144"/>
+ </imagelayer>
+ <group id="2" name="room" offsetx="10" offsety="20">
+  <imagelayer id="3" name="counter" offsetx="5" offsety="5">
+   <properties>
+    <property name="baseline" type="int" value="120"/>
+   </properties>
+   <image source="counter.png" width="64" height="32"/>
+  </imagelayer>
+  <imagelayer id="4" name="hidden" visible="0">
+   <image source="hidden.png" width="64" height="32"/>
+  </imagelayer>
+  <imagelayer id="5" name="sky" parallaxx="0.5">
+   <image source="sky.png" width="640" height="144"/>
+  </imagelayer>
+  <objectgroup id="6" name="objects" offsetx="2" offsety="4">
+   <object id="1" name="floor" x="0" y="80">
+    <properties>
+     <property name="enabled" type="bool" value="false"/>
+     <property name="farscale" type="float" value="0.5"/>
+     <property name="mask" type="int" value="0"/>
+     <property name="scale" type="float" value="0.75"/>
+    </properties>
+    <polygon points="0,0 300,0 300,40 0,40"/>
+   </object>
+   <object id="2" x="100" y="60">
+    <polygon points="0,0 20,20 -20,20"/>
+   </object>
+   <object id="3" name="door" x="136" y="40" width="48" height="60">
+    <properties>
+     <property name="usedir" value="left"/>
+     <property name="usepos" type="object" value="4"/>
+    </properties>
+   </object>
+   <object id="4" name="door use" x="160" y="104">
+    <point/>
+   </object>
+   <object id="5" name="entry" x="20" y="110">
+    <point/>
+   </object>
+  </objectgroup>
+  <group id="7" name="exits" offsetx="1" offsety="1">
+   <objectgroup id="8" name="exit points">
+    <object id="6" name="exit" x="300" y="110">
+     <point/>
+    </object>
+   </objectgroup>
+  </group>
+ </group>
+</map>
+`,
+	"hall.tmj": `{
+  "type": "map", "version": "1.10", "orientation": "orthogonal",
+  "width": 10, "height": 5, "tilewidth": 32, "tileheight": 32,
+  "layers": [
+    {"id": 1, "type": "imagelayer", "name": "background", "image": "hall.png", "visible": true},
+    {
+      "id": 2, "type": "group", "name": "room", "offsetx": 10, "offsety": 20, "visible": true,
+      "layers": [
+        {
+          "id": 3, "type": "imagelayer", "name": "counter", "image": "counter.png",
+          "offsetx": 5, "offsety": 5, "visible": true,
+          "properties": [{"name": "baseline", "type": "int", "value": 120}]
+        },
+        {"id": 4, "type": "imagelayer", "name": "hidden", "image": "hidden.png", "visible": false},
+        {"id": 5, "type": "imagelayer", "name": "sky", "image": "sky.png", "parallaxx": 0.5, "visible": true},
+        {
+          "id": 6, "type": "objectgroup", "name": "objects", "offsetx": 2, "offsety": 4, "visible": true,
+          "objects": [
+            {
+              "id": 1, "name": "floor", "x": 0, "y": 80, "width": 0, "height": 0,
+              "polygon": [{"x": 0, "y": 0}, {"x": 300, "y": 0}, {"x": 300, "y": 40}, {"x": 0, "y": 40}],
+              "properties": [
+                {"name": "enabled", "type": "bool", "value": false},
+                {"name": "farscale", "type": "float", "value": 0.5},
+                {"name": "mask", "type": "int", "value": 0},
+                {"name": "scale", "type": "float", "value": 0.75}
+              ]
+            },
+            {
+              "id": 2, "name": "", "x": 100, "y": 60, "width": 0, "height": 0,
+              "polygon": [{"x": 0, "y": 0}, {"x": 20, "y": 20}, {"x": -20, "y": 20}]
+            },
+            {
+              "id": 3, "name": "door", "x": 136, "y": 40, "width": 48, "height": 60,
+              "properties": [
+                {"name": "usedir", "type": "string", "value": "left"},
+                {"name": "usepos", "type": "object", "value": 4}
+              ]
+            },
+            {"id": 4, "name": "door use", "x": 160, "y": 104, "width": 0, "height": 0, "point": true},
+            {"id": 5, "name": "entry", "x": 20, "y": 110, "width": 0, "height": 0, "point": true}
+          ]
+        },
+        {
+          "id": 7, "type": "group", "name": "exits", "offsetx": 1, "offsety": 1, "visible": true,
+          "layers": [
+            {
+              "id": 8, "type": "objectgroup", "name": "exit points", "visible": true,
+              "objects": [
+                {"id": 6, "name": "exit", "x": 300, "y": 110, "width": 0, "height": 0, "point": true}
+              ]
+            }
+          ]
+        }
+      ]
+    }
+  ]
+}
+`,
+}
+
+// loadTiledFixture loads the fixture map in the given file.
+func loadTiledFixture(t *testing.T, name string) (*TiledMap, string) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, []byte(tiledFixtures[name]), 0o644))
+	m, err := LoadTiledMap(path)
+	require.NoError(t, err)
+	return m, dir
+}
+
+func TestLoadTiledMap(t *testing.T) {
+	for name := range tiledFixtures {
+		t.Run(name, func(t *testing.T) {
+			m, dir := loadTiledFixture(t, name)
+			assert.Equal(t, []TiledImage{
+				{Path: filepath.Join(dir, "hall.png"), ParallaxX: 1, Properties: map[string]string{}},
+				{
+					Path:       filepath.Join(dir, "counter.png"),
+					X:          15,
+					Y:          25,
+					ParallaxX:  1,
+					Properties: map[string]string{"baseline": "120"},
+				},
+				{Path: filepath.Join(dir, "sky.png"), X: 10, Y: 20, ParallaxX: 0.5, Properties: map[string]string{}},
+			}, m.Images)
+
+			var positions [][2]float64
+			for _, obj := range m.Objects {
+				positions = append(positions, [2]float64{obj.X, obj.Y})
+			}
+			assert.Equal(t, [][2]float64{{12, 104}, {112, 84}, {148, 64}, {172, 128}, {32, 134}, {311, 131}}, positions)
+			assert.Equal(t, [][2]float64{{0, 0}, {20, 20}, {-20, 20}}, m.Objects[1].Polygon)
+			assert.True(t, m.Objects[3].Point)
+		})
+	}
+}
+
+func TestTiledMap_Geometry(t *testing.T) {
+	for name := range tiledFixtures {
+		t.Run(name, func(t *testing.T) {
+			m, _ := loadTiledFixture(t, name)
+			m.Images = nil // Loading the images requires a graphics backend.
+			g, err := m.Geometry()
+			require.NoError(t, err)
+
+			assert.Equal(t, []pctk.WalkBoxGeometry{
+				{
+					ID:       "floor",
+					Vertices: []pctk.Position{{X: 12, Y: 104}, {X: 312, Y: 104}, {X: 312, Y: 144}, {X: 12, Y: 144}},
+					Scale:    0.75,
+					FarScale: 0.5,
+					Enabled:  false,
+				},
+				{
+					ID:       "walkbox2",
+					Vertices: []pctk.Position{{X: 112, Y: 84}, {X: 132, Y: 104}, {X: 92, Y: 104}},
+					Scale:    1,
+					Enabled:  true,
+				},
+			}, g.WalkBoxes)
+			assert.Equal(t, []pctk.HotspotGeometry{
+				{
+					ID:        "door",
+					Rect:      pctk.NewRect(148, 64, 48, 60),
+					UsePos:    pctk.NewPos(172, 128),
+					HasUsePos: true,
+					UseDir:    pctk.DirLeft,
+					HasUseDir: true,
+				},
+			}, g.Hotspots)
+			assert.Equal(t, []pctk.EntranceGeometry{
+				{ID: "entry", Pos: pctk.NewPos(32, 134)},
+				{ID: "exit", Pos: pctk.NewPos(311, 131)},
+			}, g.Entrances)
+		})
+	}
+}
+
+func TestTiledMap_GeometryErrors(t *testing.T) {
+	square := [][2]float64{{0, 0}, {10, 0}, {10, 10}, {0, 10}}
+	tests := []struct {
+		name string
+		obj  TiledObject
+		want string
+	}{
+		{
+			name: "too few vertices",
+			obj:  TiledObject{ID: 1, Name: "floor", Polygon: square[:2]},
+			want: "walkbox floor must have at least 3 vertices",
+		},
+		{
+			name: "concave walkbox",
+			obj:  TiledObject{ID: 1, Name: "floor", Polygon: [][2]float64{{0, 0}, {4, 0}, {2, 1}, {4, 4}}},
+			want: "walkbox floor must be a convex polygon: [(X:0, Y:0) (X:4, Y:0) (X:2, Y:1) (X:4, Y:4)]",
+		},
+		{
+			name: "invalid scale",
+			obj:  TiledObject{ID: 1, Polygon: square, Properties: map[string]string{"scale": "big"}},
+			want: `walkbox walkbox1: invalid scale "big"`,
+		},
+		{
+			name: "mask without layers",
+			obj:  TiledObject{ID: 1, Name: "floor", Polygon: square, Properties: map[string]string{"mask": "1"}},
+			want: `walkbox floor: invalid mask "1"`,
+		},
+		{
+			name: "invalid enabled",
+			obj:  TiledObject{ID: 1, Name: "floor", Polygon: square, Properties: map[string]string{"enabled": "maybe"}},
+			want: `walkbox floor: invalid enabled "maybe"`,
+		},
+		{
+			name: "unnamed hotspot",
+			obj:  TiledObject{ID: 2, W: 10, H: 10},
+			want: "object 2: hotspots must be named after their object",
+		},
+		{
+			name: "unknown use position",
+			obj:  TiledObject{ID: 2, Name: "door", W: 10, H: 10, Properties: map[string]string{"usepos": "9"}},
+			want: `hotspot door: usepos refers to unknown object "9"`,
+		},
+		{
+			name: "unnamed entrance",
+			obj:  TiledObject{ID: 3, Point: true},
+			want: "object 3: entrances must be named",
+		},
+		{
+			name: "unsupported shape",
+			obj:  TiledObject{ID: 4, Shape: "ellipse"},
+			want: "object 4: unsupported ellipse shape",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &TiledMap{Objects: []TiledObject{tt.obj}}
+			_, err := m.Geometry()
+			assert.EqualError(t, err, tt.want)
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package pack
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// WatchInterval is the interval between checks for changes in the sources being watched.
+var WatchInterval = 500 * time.Millisecond
+
+// watch packs the resources in src every time a file in it changes. It never returns unless the
+// source directory cannot be read. Packing errors are reported, and packing is retried after the
+// next change.
+func watch(src string, output string, force bool) error {
+	var last string
+	for {
+		current, err := snapshot(src, output)
+		if err != nil {
+			return err
+		}
+		if current != last {
+			if err := do(src, output, force); err != nil {
+				fmt.Fprintf(os.Stderr, "Packing failed: %v\n", err)
+			}
+			fmt.Printf("Watching %s for changes...\n", src)
+			last = current
+		}
+		time.Sleep(WatchInterval)
+	}
+}
+
+// snapshot returns a digest of the path, size and modification time of the files in dir, excluding
+// the output files.
+func snapshot(dir string, output string) (string, error) {
+	excluded := make(map[string]bool)
+	for _, ext := range []string{".idx", ".dat", ".idx.tmp", ".dat.tmp", ".cache"} {
+		path, err := filepath.Abs(output + ext)
+		if err != nil {
+			return "", err
+		}
+		excluded[path] = true
+	}
+
+	h := sha256.New()
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		if abs, err := filepath.Abs(path); err != nil || excluded[abs] {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(h, "%s:%d:%d\n", path, info.Size(), info.ModTime().UnixNano())
+		return nil
+	})
+	return string(h.Sum(nil)), err
+}
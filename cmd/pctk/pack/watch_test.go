@@ -0,0 +1,83 @@
+package pack
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshot(t *testing.T) {
+	tests := []struct {
+		name    string
+		change  func(dir string) error
+		changed bool
+	}{
+		{
+			name:    "nothing changed",
+			change:  func(dir string) error { return nil },
+			changed: false,
+		},
+		{
+			name: "modified file",
+			change: func(dir string) error {
+				return os.WriteFile(filepath.Join(dir, "scripts/boot.lua"), []byte("print('boot v2')"), 0o644)
+			},
+			changed: true,
+		},
+		{
+			name: "touched file",
+			change: func(dir string) error {
+				later := time.Now().Add(time.Hour)
+				return os.Chtimes(filepath.Join(dir, "scripts/boot.lua"), later, later)
+			},
+			changed: true,
+		},
+		{
+			name: "new file",
+			change: func(dir string) error {
+				return os.WriteFile(filepath.Join(dir, "scripts/intro.lua"), nil, 0o644)
+			},
+			changed: true,
+		},
+		{
+			name: "removed file",
+			change: func(dir string) error {
+				return os.Remove(filepath.Join(dir, "costumes/ego.yml"))
+			},
+			changed: true,
+		},
+		{
+			name: "output files",
+			change: func(dir string) error {
+				for _, ext := range []string{".idx", ".dat", ".idx.tmp", ".dat.tmp", ".cache"} {
+					if err := os.WriteFile(filepath.Join(dir, "resources"+ext), []byte("packed"), 0o644); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+			changed: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, map[string]string{
+				"scripts/boot.lua": "print('boot v1')",
+				"costumes/ego.yml": "type: costume\n",
+			})
+			output := filepath.Join(dir, "resources")
+
+			before, err := snapshot(dir, output)
+			require.NoError(t, err)
+			require.NoError(t, tt.change(dir))
+			after, err := snapshot(dir, output)
+			require.NoError(t, err)
+			assert.Equal(t, tt.changed, before != after)
+		})
+	}
+}
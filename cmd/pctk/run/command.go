@@ -0,0 +1,67 @@
+package run
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/apoloval/pctk"
+	"github.com/apoloval/pctk/project"
+	"github.com/spf13/cobra"
+)
+
+var (
+	debug bool
+	room  string
+)
+
+var Command = &cobra.Command{
+	Use:   "run [dir]",
+	Short: "run the game project described by the game.yml file in dir",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir := "."
+		if len(args) > 0 {
+			dir = args[0]
+		}
+		return do(dir, cmd.Flags().Changed("debug"))
+	},
+}
+
+func init() {
+	Command.Flags().BoolVarP(
+		&debug, "debug", "d", false, "enable or disable the debug mode, overriding the manifest",
+	)
+	Command.Flags().StringVarP(
+		&room, "room", "r", "", "start in the room exported as script#name (e.g. pkg:scripts/foo#bar)",
+	)
+}
+
+func do(dir string, debugChanged bool) error {
+	man, err := project.LoadManifest(dir)
+	if err != nil {
+		return err
+	}
+	if debugChanged {
+		man.Debug = debug
+	}
+
+	var opts []pctk.AppOption
+	if room != "" {
+		script, name, found := strings.Cut(room, "#")
+		if !found {
+			return fmt.Errorf("invalid room %q: expected script#name", room)
+		}
+		ref, err := pctk.ParseResourceRef(script)
+		if err != nil {
+			return fmt.Errorf("invalid room %q: %w", room, err)
+		}
+		opts = append(opts, pctk.WithStartRoom(ref, name))
+	}
+
+	app, err := man.NewApp(opts...)
+	if err != nil {
+		return err
+	}
+	app.Run()
+	return nil
+}
@@ -0,0 +1,15 @@
+package scaffold
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var Command = &cobra.Command{
+	Use:   "new <name>",
+	Short: "create a new game project with a sample room and actor",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		return do(args[0])
+	},
+}
@@ -0,0 +1,225 @@
+package scaffold
+
+import (
+	"embed"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/apoloval/pctk"
+)
+
+//go:embed template
+var templates embed.FS
+
+// defaultReplies are the sentences said by the ego when a verb has no specific behavior.
+var defaultReplies = map[pctk.Verb]string{
+	pctk.VerbOpen:    "I can't open that.",
+	pctk.VerbClose:   "I can't close that.",
+	pctk.VerbPush:    "I can't push that.",
+	pctk.VerbPull:    "I can't pull that.",
+	pctk.VerbPickUp:  "I can't pick that up.",
+	pctk.VerbTalkTo:  "I can't talk to that.",
+	pctk.VerbGive:    "I can't give that.",
+	pctk.VerbUse:     "I can't use that.",
+	pctk.VerbLookAt:  "There is nothing special about that.",
+	pctk.VerbTurnOn:  "I can't turn that on.",
+	pctk.VerbTurnOff: "I can't turn that off.",
+}
+
+type projectData struct {
+	Module string
+	Title  string
+	Verbs  []verbData
+}
+
+type verbData struct {
+	Action string
+	Reply  string
+}
+
+func do(name string) error {
+	if _, err := os.Stat(name); err == nil {
+		return fmt.Errorf("%s already exists", name)
+	}
+
+	base := filepath.Base(name)
+	module, err := modulePath(base)
+	if err != nil {
+		return err
+	}
+	first, size := utf8.DecodeRuneInString(base)
+	data := projectData{
+		Module: module,
+		Title:  string(unicode.ToUpper(first)) + base[size:],
+	}
+	for _, verb := range pctk.Verbs {
+		data.Verbs = append(data.Verbs, verbData{
+			Action: verb.Action(),
+			Reply:  defaultReplies[verb],
+		})
+	}
+
+	err = fs.WalkDir(templates, "template", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel := strings.TrimPrefix(path, "template/")
+		return generateFile(filepath.Join(name, strings.TrimSuffix(rel, ".tmpl")), path, data)
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := writeImage(filepath.Join(name, "resources/backgrounds/hall.png"), drawBackground()); err != nil {
+		return err
+	}
+	if err := writeImage(filepath.Join(name, "resources/costumes/ego.png"), drawCostume()); err != nil {
+		return err
+	}
+
+	fmt.Printf("Project %s created. To play it:\n", name)
+	fmt.Printf("  cd %s && go mod tidy && make run\n", name)
+	return nil
+}
+
+// templateFuncs are the functions available to the templates to quote values in the generated files.
+var templateFuncs = template.FuncMap{
+	"lua":  luaString,
+	"yaml": strconv.Quote,
+}
+
+func generateFile(path, tmplPath string, data projectData) error {
+	tmpl, err := template.New(filepath.Base(tmplPath)).Funcs(templateFuncs).ParseFS(templates, tmplPath)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return tmpl.Execute(file, data)
+}
+
+// modulePath returns the path of the Go module of a project with the given name, made of the ASCII
+// letters and digits of the name in lowercase and separated by dashes.
+func modulePath(name string) (string, error) {
+	var b strings.Builder
+	sep := false
+	for _, r := range strings.ToLower(name) {
+		if r >= utf8.RuneSelf || !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			sep = true
+			continue
+		}
+		if sep && b.Len() > 0 {
+			b.WriteByte('-')
+		}
+		sep = false
+		b.WriteRune(r)
+	}
+	if b.Len() == 0 {
+		return "", fmt.Errorf("cannot derive a Go module path from %q, use letters or digits in the name", name)
+	}
+	return b.String(), nil
+}
+
+// luaString returns the given text as a Lua string literal, escaping quotes, backslashes and control
+// characters.
+func luaString(text string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; {
+		case c == '"' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c < 0x20 || c == 0x7F:
+			fmt.Fprintf(&b, "\\%03d", c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+func writeImage(path string, img image.Image) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return png.Encode(file, img)
+}
+
+// drawBackground draws a placeholder background for the sample room: a wall with a door and a
+// floor matching the sample walkbox.
+func drawBackground() image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, pctk.ScreenWidth, pctk.ViewportHeight))
+	fillRect(img, img.Bounds(), color.RGBA{0x55, 0x55, 0x55, 0xFF})
+	for y := 100; y < pctk.ViewportHeight; y++ {
+		// The floor is a trapezoid that widens towards the bottom of the screen.
+		margin := max(50-(y-100), 10)
+		fillRect(img, image.Rect(margin, y, pctk.ScreenWidth-margin, y+1),
+			color.RGBA{0xAA, 0x55, 0x00, 0xFF})
+	}
+	fillRect(img, image.Rect(136, 40, 184, 100), color.RGBA{0x55, 0x2A, 0x00, 0xFF})
+	fillRect(img, image.Rect(174, 68, 178, 72), color.RGBA{0xFF, 0xFF, 0x55, 0xFF})
+	return img
+}
+
+// drawCostume draws a placeholder sprite sheet for the ego costume with frames of 32x48 pixels.
+// Rows are side, front and back views. Columns 0-1 are idle and speaking frames, and columns 2-5 are
+// the walking cycle.
+func drawCostume() image.Image {
+	const w, h = 32, 48
+	img := image.NewRGBA(image.Rect(0, 0, 6*w, 3*h))
+	skin := color.RGBA{0xFF, 0xAA, 0x55, 0xFF}
+	shirt := color.RGBA{0xFF, 0xFF, 0xFF, 0xFF}
+	pants := color.RGBA{0x00, 0x00, 0xAA, 0xFF}
+	mouth := color.RGBA{0xAA, 0x00, 0x00, 0xFF}
+	hair := color.RGBA{0xFF, 0xFF, 0x55, 0xFF}
+	for row := 0; row < 3; row++ {
+		for col := 0; col < 6; col++ {
+			x, y := col*w, row*h
+			fillRect(img, image.Rect(x+11, y+2, x+21, y+12), skin)
+			fillRect(img, image.Rect(x+11, y+2, x+21, y+5), hair)
+			if row == 2 {
+				fillRect(img, image.Rect(x+11, y+2, x+21, y+12), hair)
+			}
+			fillRect(img, image.Rect(x+9, y+12, x+23, y+30), shirt)
+
+			// Legs move along the walking cycle.
+			stride := []int{0, 0, -3, 0, 3, 0}[col]
+			fillRect(img, image.Rect(x+10+stride, y+30, x+15+stride, y+48), pants)
+			fillRect(img, image.Rect(x+17-stride, y+30, x+22-stride, y+48), pants)
+
+			// Speaking frame opens the mouth.
+			if col == 1 && row != 2 {
+				fillRect(img, image.Rect(x+15, y+9, x+18, y+11), mouth)
+			}
+		}
+	}
+	return img
+}
+
+func fillRect(img *image.RGBA, r image.Rectangle, c color.Color) {
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			img.Set(x, y, c)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package scaffold
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Shopify/go-lua"
+	"github.com/apoloval/pctk/project"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLuaString(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{text: "Monkey Island", want: `"Monkey Island"`},
+		{text: `The "Secret"`, want: `"The \"Secret\""`},
+		{text: `C:\games`, want: `"C:\\games"`},
+		{text: "two\nlines", want: `"two\010lines"`},
+		{text: "Café", want: `"Café"`},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, luaString(tt.text))
+	}
+}
+
+func TestModulePath(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{name: "island", want: "island"},
+		{name: "Monkey Island 2", want: "monkey-island-2"},
+		{name: `say "hi": \o`, want: "say-hi-o"},
+		{name: "..game.v1_", want: "game-v1"},
+		{name: "ñandú", want: "and"},
+		{name: "...", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := modulePath(tt.name)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDo_QuotesTitle(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), `say "hi": \o`)
+	require.NoError(t, do(dir))
+
+	man, err := project.LoadManifest(dir)
+	require.NoError(t, err)
+	assert.Equal(t, `Say "hi": \o`, man.Title)
+
+	gomod, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	require.NoError(t, err)
+	assert.Equal(t, "module say-hi-o\n\ngo 1.22\n", string(gomod))
+
+	scripts, err := os.ReadDir(filepath.Join(dir, "resources", "scripts"))
+	require.NoError(t, err)
+	for _, script := range scripts {
+		if filepath.Ext(script.Name()) != ".lua" {
+			continue
+		}
+		path := filepath.Join(dir, "resources", "scripts", script.Name())
+		code, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.NoError(t, lua.LoadBuffer(lua.NewState(), string(code), path, ""), path)
+	}
+}
+
+func TestDo_CapitalizesTitle(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "ñandú")
+	require.NoError(t, do(dir))
+
+	man, err := project.LoadManifest(dir)
+	require.NoError(t, err)
+	assert.Equal(t, "Ñandú", man.Title)
+}
+
+func TestDo_InvalidName(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "...")
+	assert.Error(t, do(dir))
+	assert.NoDirExists(t, dir)
+}
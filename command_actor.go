@@ -64,6 +64,54 @@ func (cmd ActorLookAtPos) Execute(app *App, done *Promise) {
 	done.Bind(cmd.Actor.Do(Standing(cmd.Actor.DirectionTo(cmd.Position))))
 }
 
+// ActorPlay is a command that will make an actor play a named custom action of its costume. If no
+// direction is given, the actor plays it in the direction it is looking at. The command completes
+// when the animation is over, unless it loops. If UntilConnect is set, it completes when the
+// animation shows the frame where the action connects instead.
+type ActorPlay struct {
+	Actor        *Actor
+	Action       string
+	Direction    *Direction
+	Loop         bool
+	UntilConnect bool
+}
+
+func (cmd ActorPlay) Execute(app *App, done *Promise) {
+	dir := cmd.Actor.lookAt
+	if cmd.Direction != nil {
+		dir = *cmd.Direction
+	}
+	if cos := cmd.Actor.costume; cos != nil {
+		if _, ok := cos.NamedAction(cmd.Action, dir); !ok {
+			done.CompleteWithErrorf("actor %s has no costume action %s", cmd.Actor.Caption(), cmd.Action)
+			return
+		}
+	}
+	var connected *Promise
+	if cmd.UntilConnect {
+		connected = NewPromise()
+	}
+	finished := cmd.Actor.Do(Playing(cmd.Action, dir, cmd.Loop, connected))
+	if connected != nil {
+		done.Bind(connected)
+		return
+	}
+	done.Bind(finished)
+}
+
+// ActorOnFrameEvent is a command that will set the callback invoked when an animation of an actor
+// emits the given frame event.
+type ActorOnFrameEvent struct {
+	Actor    *Actor
+	Event    string
+	Callback *ScriptCallback
+}
+
+func (cmd ActorOnFrameEvent) Execute(app *App, done *Promise) {
+	cmd.Actor.OnFrameEvent(cmd.Event, cmd.Callback)
+	done.Complete()
+}
+
 // ActorStand is a command that will make an actor stand in the given direction.
 type ActorStand struct {
 	Actor     *Actor
@@ -217,6 +265,19 @@ func (cmd ActorInteractWith) Execute(app *App, done *Promise) {
 						Args:     args,
 					},
 				)
+			} else if cmd.Verb == VerbWalkTo && item.IsPassable() && item.FindCallback(VerbWalkTo.Action()) == nil {
+				// Special case: walk to an exit with no walk to callback. Must walk to it and
+				// then go through it to the other room.
+				completed = app.RunCommandSequence(
+					ActorWalkToItem{
+						Actor: cmd.Actor,
+						Item:  item,
+					},
+					ObjectExitThrough{
+						Actor: cmd.Actor,
+						Exit:  item,
+					},
+				)
 			} else {
 				// General case. Walk to it and then interact.
 				completed = app.RunCommandSequence(
@@ -21,26 +21,86 @@ func (cmd ObjectCall) Execute(app *App, done *Promise) {
 	done.Bind(app.defaults.CallFunction(cmd.Function, args))
 }
 
-// ObjectSetState set the state  of the object.
+// ObjectSetState set the state  of the object. If the object is a door paired with another one, the
+// other side is set to the state with the same name too.
 func ObjectSetState(st *ObjectState) CommandFunc {
 	return func(a *App) (any, error) {
-		st.Object.State = st
+		obj := st.Object
+		obj.State = st
+		if door := obj.pairedDoor(); door != nil {
+			if other, ok := door.States[obj.stateName(st)]; ok {
+				door.State = other
+			}
+		}
 		return nil, nil
 	}
 }
 
-// ObjectEnableClass is a command that will enable a class of an object.
+// ObjectEnableClass is a command that will enable a class of an object. If the object is a door
+// paired with another one, the openable and closeable classes are enabled in the other side too.
 func ObjectEnableClass(obj *Object, class ObjectClass) CommandFunc {
 	return func(a *App) (any, error) {
 		obj.EnableClass(class)
+		if door := obj.pairedDoor(); door != nil {
+			door.EnableClass(class & doorClasses)
+		}
 		return nil, nil
 	}
 }
 
-// ObjectDisableClass is a command that will disable a class of an object.
+// ObjectDisableClass is a command that will disable a class of an object. If the object is a door
+// paired with another one, the openable and closeable classes are disabled in the other side too.
 func ObjectDisableClass(obj *Object, class ObjectClass) CommandFunc {
 	return func(a *App) (any, error) {
 		obj.DisableClass(class)
+		if door := obj.pairedDoor(); door != nil {
+			door.DisableClass(class & doorClasses)
+		}
 		return nil, nil
 	}
 }
+
+// ObjectSetExit is a command that will set the room the object leads to, or make it no longer an
+// exit if nil.
+func ObjectSetExit(obj *Object, exit *ObjectExit) CommandFunc {
+	return func(a *App) (any, error) {
+		obj.Exit = exit
+		return nil, nil
+	}
+}
+
+// doorClasses are the classes shared by the two sides of a door.
+const doorClasses = ObjectClassOpenable | ObjectClassCloseable
+
+// ObjectExitThrough is a command that will make an actor leave the room through an exit object and
+// enter the room it leads to through its entrance. The room is shown with the transition of the
+// exit, and the actor appears in the new room once the old one is covered.
+type ObjectExitThrough struct {
+	Actor *Actor
+	Exit  *Object
+}
+
+func (cmd ObjectExitThrough) Execute(app *App, done *Promise) {
+	entrance, err := cmd.Exit.Entrance()
+	if err != nil {
+		done.CompleteWithError(err)
+		return
+	}
+	if entrance == nil {
+		done.CompleteWithErrorf("object '%s' is not an exit", cmd.Exit.Name)
+		return
+	}
+	tr := cmd.Exit.Exit.Transition
+	var covered Future = AlreadySucceeded(nil)
+	if tr.Effect != TransitionCut {
+		covered = app.viewport.TransitionOut(tr.Effect, tr.Duration/2, tr.Center)
+	}
+	done.Bind(Continue(covered, func(any) Future {
+		return app.RunCommand(CommandAsyncFunc(func(app *App) Future {
+			if err := app.ActorEnter(cmd.Actor, entrance); err != nil {
+				return AlreadyFailed(err)
+			}
+			return app.StartRoom(entrance.Room, entrance, tr)
+		}))
+	}))
+}
@@ -1,5 +1,7 @@
 package pctk
 
+import "time"
+
 // RoomDeclare is a command that will declare a new room with the given properties.
 type RoomDeclare struct {
 	Room *Room
@@ -15,27 +17,66 @@ func (cmd RoomDeclare) Execute(app *App, done *Promise) {
 
 // RoomShow is a command that will show the room with the given resource.
 type RoomShow struct {
-	Room     *Room
-	Entrance *Object
+	Room       *Room
+	Entrance   *Object
+	Transition Transition
 }
 
 func (cmd RoomShow) Execute(app *App, done *Promise) {
-	done.Bind(app.StartRoom(cmd.Room, cmd.Entrance))
+	done.Bind(app.StartRoom(cmd.Room, cmd.Entrance, cmd.Transition))
 }
 
 // RoomCameraTo is a command that will move the camera to the given position.
-func RoomCameraTo(vp *Viewport, pos int) CommandFunc {
+func RoomCameraTo(vp *Viewport, pos Position) CommandFunc {
 	return func(a *App) (any, error) {
 		vp.CameraMoveTo(pos)
 		return nil, nil
 	}
 }
 
+// RoomCameraToX is a command that will move the camera to the given horizontal position, keeping
+// its vertical position.
+func RoomCameraToX(vp *Viewport, x int) CommandFunc {
+	return func(a *App) (any, error) {
+		vp.CameraMoveTo(NewPos(x, int(vp.campos.Y)))
+		return nil, nil
+	}
+}
+
+// RoomCameraPan is a command that will pan the camera to the given position in the given duration.
+func RoomCameraPan(vp *Viewport, pos Position, duration time.Duration) CommandAsyncFunc {
+	return func(a *App) Future {
+		return vp.CameraPanTo(pos, duration)
+	}
+}
+
+// RoomCameraZoom is a command that will change the zoom of the camera in the given duration.
+func RoomCameraZoom(vp *Viewport, zoom float32, duration time.Duration) CommandAsyncFunc {
+	return func(a *App) Future {
+		return vp.CameraZoomTo(zoom, duration)
+	}
+}
+
+// RoomCameraShake is a command that will shake the camera with the given intensity for the given
+// duration.
+func RoomCameraShake(vp *Viewport, intensity float32, duration time.Duration) CommandAsyncFunc {
+	return func(a *App) Future {
+		return vp.CameraShake(intensity, duration)
+	}
+}
+
+// RoomCameraDeadZone is a command that will set the dead zone of the camera when following actors.
+func RoomCameraDeadZone(vp *Viewport, size Size) CommandFunc {
+	return func(a *App) (any, error) {
+		vp.CameraDeadZone(size)
+		return nil, nil
+	}
+}
+
 // RoomCameraFollowActor is a command that will make the camera follow the given actor.
 func RoomCameraFollowActor(vp *Viewport, actor *Actor) CommandFunc {
 	return func(a *App) (any, error) {
-		vp.CameraFollowActor(actor)
-		return nil, nil
+		return nil, vp.CameraFollowActor(actor)
 	}
 }
 
@@ -54,3 +95,72 @@ func RoomCameraOnRightEdge(vp *Viewport) CommandFunc {
 		return nil, nil
 	}
 }
+
+// RoomTransitionOut is a command that will cover the viewport with the given effect.
+func RoomTransitionOut(vp *Viewport, effect TransitionEffect, duration time.Duration, center *Actor) CommandAsyncFunc {
+	return func(a *App) Future {
+		return vp.TransitionOut(effect, duration, center)
+	}
+}
+
+// RoomTransitionIn is a command that will uncover the viewport with the given effect.
+func RoomTransitionIn(vp *Viewport, effect TransitionEffect, duration time.Duration, center *Actor) CommandAsyncFunc {
+	return func(a *App) Future {
+		return vp.TransitionIn(effect, duration, center)
+	}
+}
+
+// RoomTint is a command that will change the color drawn over the viewport.
+func RoomTint(vp *Viewport, color Color, duration time.Duration) CommandAsyncFunc {
+	return func(a *App) Future {
+		return vp.TintTo(color, duration)
+	}
+}
+
+// RoomAmbient is a command that will set the color a room and everything in it is tinted with.
+func RoomAmbient(room *Room, color Color) CommandFunc {
+	return func(a *App) (any, error) {
+		room.Ambient = color
+		return nil, nil
+	}
+}
+
+// RoomDarkness is a command that will set how dark a room is out of its lights.
+func RoomDarkness(room *Room, darkness float32) CommandFunc {
+	return func(a *App) (any, error) {
+		room.Darkness = darkness
+		return nil, nil
+	}
+}
+
+// RoomLightOn is a command that will turn on a light carried by an actor in a room.
+func RoomLightOn(room *Room, actor *Actor, radius float32) CommandFunc {
+	return func(a *App) (any, error) {
+		room.LightOn(actor, radius)
+		return nil, nil
+	}
+}
+
+// RoomLightOff is a command that will turn off the light carried by an actor in a room.
+func RoomLightOff(room *Room, actor *Actor) CommandFunc {
+	return func(a *App) (any, error) {
+		room.LightOff(actor)
+		return nil, nil
+	}
+}
+
+// RoomStartTimer is a command that will start a timer in a room.
+func RoomStartTimer(room *Room, t *RoomTimer) CommandFunc {
+	return func(a *App) (any, error) {
+		room.StartTimer(t, time.Now())
+		return nil, nil
+	}
+}
+
+// RoomCancelTimer is a command that will cancel a timer.
+func RoomCancelTimer(t *RoomTimer) CommandFunc {
+	return func(a *App) (any, error) {
+		t.Cancel()
+		return nil, nil
+	}
+}
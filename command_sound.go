@@ -1,12 +1,24 @@
 package pctk
 
-// SoundPlay is a command that will play the sound with the given resource reference.
+import "fmt"
+
+// SoundPlay is a command that will play the sound with the given resource reference. The sound is
+// panned by the item or the position of the room it comes from, or centered if none is given.
 type SoundPlay struct {
 	Sound *Sound
+	From  RoomItem  // The item of the room the sound comes from, if any.
+	Pos   *Position // The position of the room the sound comes from, if any.
 }
 
 func (cmd SoundPlay) Execute(app *App, done *Promise) {
-	cmd.Sound.Play(app)
+	switch {
+	case cmd.From != nil:
+		app.PlaySoundAt(cmd.Sound, cmd.From.ItemPosition())
+	case cmd.Pos != nil:
+		app.PlaySoundAt(cmd.Sound, *cmd.Pos)
+	default:
+		cmd.Sound.Play(app)
+	}
 	done.Complete()
 }
 
@@ -19,3 +31,16 @@ func (cmd SoundStop) Execute(app *App, done *Promise) {
 	cmd.Sound.Stop(app)
 	done.Complete()
 }
+
+// SoundEmitterEnable is a command that will enable or disable the sound emitter of a room with the
+// given name.
+func SoundEmitterEnable(room *Room, name string, enabled bool) CommandFunc {
+	return func(a *App) (any, error) {
+		e := room.SoundEmitter(name)
+		if e == nil {
+			return nil, fmt.Errorf("sound emitter '%s' not found", name)
+		}
+		e.Enabled = enabled
+		return nil, nil
+	}
+}
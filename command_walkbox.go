@@ -15,3 +15,12 @@ func DisableWalkBox(w *WalkBox) CommandFunc {
 		return nil, nil
 	}
 }
+
+// WalkBoxLight is a command that will set the color the actors standing in a walkbox are tinted
+// with.
+func WalkBoxLight(w *WalkBox, c Color) CommandFunc {
+	return func(a *App) (any, error) {
+		w.WithLight(c)
+		return nil, nil
+	}
+}
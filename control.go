@@ -33,6 +33,13 @@ const (
 	VerbTurnOff Verb = "Turn off"
 )
 
+// Verbs is the list of all built-in verbs.
+var Verbs = []Verb{
+	VerbOpen, VerbClose, VerbPush, VerbPull,
+	VerbWalkTo, VerbPickUp, VerbTalkTo, VerbGive,
+	VerbUse, VerbLookAt, VerbTurnOn, VerbTurnOff,
+}
+
 const (
 	// SentenceChoiceMagin is the margin of sentence choice in the control pane.
 	SentenceChoiceMagin = 2
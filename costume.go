@@ -1,12 +1,22 @@
 package pctk
 
 import (
+	"fmt"
 	"io"
+	"slices"
 )
 
+// MaxCostumeNamedActions is the maximum number of named custom actions a costume can declare.
+const MaxCostumeNamedActions = 16
+
+// CostumeCustomAction is the lowest value of the custom actions that are not named.
+const CostumeCustomAction CostumeAction = 0x80
+
 // CostumeAction is a value that represents an action for a costume. For predefined actions idle,
 // speak, and walk, use the CustomIdle, CustomSpeak, and CustomWalk functions respectively to refer
-// to them. For custom actions, use any custom byte value above 0x80.
+// to them. Named custom actions are declared in the costume with DeclareNamedAction, and they take
+// the values from 0x40 to 0x7F. For other custom actions, use any custom byte value from
+// CostumeCustomAction.
 type CostumeAction byte
 
 // CostumeIdle returns a costume action for the idle action in the given direction.
@@ -24,11 +34,16 @@ func CostumeWalk(dir Direction) CostumeAction {
 	return CostumeAction((2 << 2) | (dir & 0x03))
 }
 
+func costumeNamed(n int, dir Direction) CostumeAction {
+	return CostumeAction(0x40 | (n << 2) | int(dir&0x03))
+}
+
 // Costume is a struct that represents a costume for an actor or a room animation.
 type Costume struct {
 	sprites *SpriteSheet
 
 	anims map[CostumeAction]*Animation
+	names []string // The names of the custom actions, in the order they were declared.
 }
 
 // NewCostume creates a new costume.
@@ -45,16 +60,63 @@ func (c *Costume) WithAnimation(act CostumeAction, anim *Animation) *Costume {
 	return c
 }
 
+// DeclareNamedAction returns the action for the named custom action in the given direction,
+// declaring the name in the costume if it is new.
+func (c *Costume) DeclareNamedAction(name string, dir Direction) (CostumeAction, error) {
+	if act, ok := c.NamedAction(name, dir); ok {
+		return act, nil
+	}
+	if len(c.names) >= MaxCostumeNamedActions {
+		return 0, fmt.Errorf("too many named actions, at most %d are allowed", MaxCostumeNamedActions)
+	}
+	c.names = append(c.names, name)
+	return costumeNamed(len(c.names)-1, dir), nil
+}
+
+// NamedAction returns the action for the named custom action in the given direction. It returns
+// false if the costume does not declare the name.
+func (c *Costume) NamedAction(name string, dir Direction) (CostumeAction, bool) {
+	n := slices.Index(c.names, name)
+	if n < 0 {
+		return 0, false
+	}
+	return costumeNamed(n, dir), true
+}
+
 // BinaryEncode encodes the costume to a binary format. The format is as follows:
 // - sprite sheet.
 // - uint32: the number of animations.
-// - for each animation:
+// - for each animation, sorted by action:
 //   - byte: the action.
 //   - the animation.
+//
+// - uint16: the number of named actions, followed by their names in declaration order.
 func (c *Costume) BinaryEncode(w io.Writer) (n int, err error) {
 	n, err = BinaryEncode(w, c.sprites, uint32(len(c.anims)))
-	for act, anim := range c.anims {
-		nn, err := BinaryEncode(w, byte(act), anim)
+	if err != nil {
+		return n, err
+	}
+
+	acts := make([]CostumeAction, 0, len(c.anims))
+	for act := range c.anims {
+		acts = append(acts, act)
+	}
+	slices.Sort(acts)
+	for _, act := range acts {
+		nn, err := BinaryEncode(w, byte(act), c.anims[act])
+		n += nn
+		if err != nil {
+			return n, err
+		}
+	}
+
+	nn, err := BinaryEncode(w, uint16(len(c.names)))
+	n += nn
+	if err != nil {
+		return n, err
+	}
+	for _, name := range c.names {
+		nn, err := BinaryEncode(w, name)
 		n += nn
 		if err != nil {
 			return n, err
@@ -80,11 +142,26 @@ func (c *Costume) BinaryDecode(r io.Reader) error {
 		}
 		c.anims[CostumeAction(act)] = anim
 	}
+
+	var names uint16
+	if err := BinaryDecode(r, &names); err != nil {
+		return err
+	}
+	c.names = make([]string, names)
+	for i := range c.names {
+		if err := BinaryDecode(r, &c.names[i]); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-func (c *Costume) draw(act CostumeAction, pos Position) {
-	if anim := c.anims[act]; anim != nil {
-		anim.Draw(c.sprites, pos)
-	}
+func (c *Costume) draw(p *AnimationPlayer, act CostumeAction, pos Position, scale float32) {
+	p.Play(c.anims[act])
+	p.Draw(c.sprites, pos, scale)
+}
+
+func (c *Costume) drawOnce(p *AnimationPlayer, act CostumeAction, pos Position, scale float32) bool {
+	p.Play(c.anims[act])
+	return p.DrawOnce(c.sprites, pos, scale)
 }
@@ -0,0 +1,50 @@
+package pctk_test
+
+import (
+	"testing"
+
+	"github.com/apoloval/pctk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCostume_DeclareNamedAction(t *testing.T) {
+	costume := pctk.NewCostume(nil)
+
+	pickupLeft, err := costume.DeclareNamedAction("pickup", pctk.DirLeft)
+	require.NoError(t, err)
+	pickupRight, err := costume.DeclareNamedAction("pickup", pctk.DirRight)
+	require.NoError(t, err)
+	dance, err := costume.DeclareNamedAction("dance", pctk.DirLeft)
+	require.NoError(t, err)
+
+	assert.NotEqual(t, pickupLeft, pickupRight)
+	assert.NotEqual(t, pickupLeft, dance)
+	assert.Less(t, dance, pctk.CostumeCustomAction)
+	for _, dir := range []pctk.Direction{pctk.DirRight, pctk.DirLeft, pctk.DirUp, pctk.DirDown} {
+		assert.NotEqual(t, pctk.CostumeIdle(dir), pickupLeft)
+		assert.NotEqual(t, pctk.CostumeSpeak(dir), pickupLeft)
+		assert.NotEqual(t, pctk.CostumeWalk(dir), pickupLeft)
+	}
+
+	act, ok := costume.NamedAction("pickup", pctk.DirLeft)
+	assert.True(t, ok)
+	assert.Equal(t, pickupLeft, act)
+	_, ok = costume.NamedAction("push", pctk.DirLeft)
+	assert.False(t, ok)
+}
+
+func TestCostume_DeclareNamedActionTooMany(t *testing.T) {
+	costume := pctk.NewCostume(nil)
+	for i := 0; i < pctk.MaxCostumeNamedActions; i++ {
+		_, err := costume.DeclareNamedAction(string(rune('a'+i)), pctk.DirRight)
+		require.NoError(t, err)
+	}
+	_, err := costume.DeclareNamedAction("one more", pctk.DirRight)
+	assert.Error(t, err)
+
+	// Named actions never reach the values of the custom actions that are not named.
+	last, ok := costume.NamedAction(string(rune('a'+pctk.MaxCostumeNamedActions-1)), pctk.DirDown)
+	assert.True(t, ok)
+	assert.Less(t, last, pctk.CostumeCustomAction)
+}
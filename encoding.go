@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"compress/gzip"
 	"encoding/binary"
+	"fmt"
 	"io"
 	"log"
 	"os"
@@ -12,7 +13,7 @@ import (
 
 const (
 	// ResourceFormatVersion
-	ResourceFormatVersion uint16 = 0x0001
+	ResourceFormatVersion uint16 = 0x0009
 )
 
 // BinaryEncode encodes objects to a writer using the binary format. If the object implements the
@@ -154,6 +155,26 @@ func (e *ResourceEncoder) EncodeScript(id ResourceID, s *Script, comp ResourceCo
 	})
 }
 
+// EncodeRoom encodes a room description using the resource encoder.
+func (e *ResourceEncoder) EncodeRoom(id ResourceID, d *RoomDescription, comp ResourceCompression) error {
+	return e.encodeResource(id, d, resourceHeader{
+		Type:        resourceTypeRoom,
+		Compression: comp,
+	})
+}
+
+// EncodeRoomGeometry encodes a room geometry using the resource encoder.
+func (e *ResourceEncoder) EncodeRoomGeometry(
+	id ResourceID,
+	g *RoomGeometry,
+	comp ResourceCompression,
+) error {
+	return e.encodeResource(id, g, resourceHeader{
+		Type:        resourceTypeRoomGeometry,
+		Compression: comp,
+	})
+}
+
 // EncodeSound encodes a sound using the resource encoder.
 func (e *ResourceEncoder) EncodeSound(id ResourceID, s *SoundTrack, comp ResourceCompression) error {
 	return e.encodeResource(id, s, resourceHeader{
@@ -174,6 +195,21 @@ func (e *ResourceEncoder) EncodeSpriteSheet(
 	})
 }
 
+// EncodeRaw writes a resource that was already encoded, as returned by
+// ResourceFileLoader.LoadRaw. This is useful to copy resources between packages without decoding
+// and encoding them again.
+func (e *ResourceEncoder) EncodeRaw(id ResourceID, data []byte) error {
+	n, err := e.data.Write(data)
+	if err != nil {
+		return err
+	}
+	if err := e.encodeIndexEntry(id, e.next, n); err != nil {
+		return err
+	}
+	e.next += n
+	return nil
+}
+
 func (e *ResourceEncoder) encodeResource(id ResourceID, res BinaryEncoder, h resourceHeader) error {
 	var n int
 	var err error
@@ -277,6 +313,18 @@ func (l *ResourceFileLoader) LoadMusic(ref ResourceRef) *MusicTrack {
 	return m
 }
 
+func (l *ResourceFileLoader) LoadRoom(ref ResourceRef) *RoomDescription {
+	d := new(RoomDescription)
+	l.decodeResource(ref, resourceTypeRoom, d)
+	return d
+}
+
+func (l *ResourceFileLoader) LoadRoomGeometry(ref ResourceRef) *RoomGeometry {
+	g := new(RoomGeometry)
+	l.decodeResource(ref, resourceTypeRoomGeometry, g)
+	return g
+}
+
 func (l *ResourceFileLoader) LoadScript(ref ResourceRef) *Script {
 	script := new(Script)
 	script.ref = ref
@@ -296,6 +344,83 @@ func (l *ResourceFileLoader) LoadSpriteSheet(ref ResourceRef) *SpriteSheet {
 	return ss
 }
 
+// Preload reads the index of the given package, so it is checked when called instead of when the
+// first resource of the package is loaded.
+func (l *ResourceFileLoader) Preload(pkg ResourcePackage) error {
+	_, err := l.readIndex(pkg)
+	return err
+}
+
+// ListResources returns the type of each resource in the given package indexed by resource ID.
+// Types are named as in resource manifests (e.g. "costume", "image"...). Unlike the load
+// functions, it returns an error if the package files cannot be read.
+func (l *ResourceFileLoader) ListResources(pkg ResourcePackage) (map[ResourceID]string, error) {
+	idxFile, err := os.Open(filepath.Join(l.path, pkg.String()+".idx"))
+	if err != nil {
+		return nil, err
+	}
+	defer idxFile.Close()
+
+	datFile, err := os.Open(filepath.Join(l.path, pkg.String()+".dat"))
+	if err != nil {
+		return nil, err
+	}
+	defer datFile.Close()
+
+	if err := checkResourceFileHeader(idxFile, resourceIndexMagic, idxFile.Name()); err != nil {
+		return nil, err
+	}
+	if err := checkResourceFileHeader(datFile, resourceDataMagic, datFile.Name()); err != nil {
+		return nil, err
+	}
+
+	resources := make(map[ResourceID]string)
+	for {
+		var entry indexEntry
+		if err := BinaryDecode(idxFile, &entry); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, fmt.Errorf("error decoding index entry: %w", err)
+		}
+		if _, err := datFile.Seek(int64(entry.Offset), io.SeekStart); err != nil {
+			return nil, err
+		}
+		var rh resourceHeader
+		if err := BinaryDecode(datFile, &rh); err != nil {
+			return nil, fmt.Errorf("error decoding header of resource %s: %w", entry.ID, err)
+		}
+		resources[entry.ID] = rh.Type.String()
+	}
+	return resources, nil
+}
+
+// LoadRaw loads the encoded data of a resource, including its header and without decompressing
+// it. The result can be written to another package using ResourceEncoder.EncodeRaw. Unlike the
+// other load functions, it returns an error if the resource cannot be read.
+func (l *ResourceFileLoader) LoadRaw(ref ResourceRef) ([]byte, error) {
+	entries, err := l.readIndex(ref.Package())
+	if err != nil {
+		return nil, err
+	}
+	entry, ok := entries[ref.ID()]
+	if !ok {
+		return nil, fmt.Errorf("resource not found: %s", ref)
+	}
+
+	file, err := os.Open(filepath.Join(l.path, ref.Package().String()+".dat"))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	data := make([]byte, entry.Size)
+	if _, err := file.ReadAt(data, int64(entry.Offset)); err != nil {
+		return nil, fmt.Errorf("error reading resource %s: %w", ref, err)
+	}
+	return data, nil
+}
+
 func (l *ResourceFileLoader) decodeResource(ref ResourceRef, t resourceType, res BinaryDecoder) {
 	data := bytes.NewReader(l.getResource(ref, t))
 	if err := BinaryDecode(data, res); err != nil {
@@ -366,19 +491,36 @@ func (l *ResourceFileLoader) getIndexEntry(ref ResourceRef) indexEntry {
 }
 
 func (l *ResourceFileLoader) loadIndex(ref ResourceRef) index {
-	idxPath := filepath.Join(l.path, ref.Package().String()+".idx")
+	idx, err := l.readIndex(ref.Package())
+	if err != nil {
+		log.Fatalf("error loading index file for ref %s: %v", ref, err)
+	}
+	return idx
+}
+
+func (l *ResourceFileLoader) readIndex(pkg ResourcePackage) (index, error) {
+	if idx, ok := l.indexes[pkg]; ok {
+		return idx, nil
+	}
+
+	idxPath := filepath.Join(l.path, pkg.String()+".idx")
 	idxFile, err := os.Open(idxPath)
 	if err != nil {
-		log.Fatalf("error opening index file for ref %s: %v", ref, err)
+		return nil, err
 	}
 	defer idxFile.Close()
 
-	var h resourceFileHeader
-	if err := BinaryDecode(idxFile, &h); err != nil {
-		log.Fatalf("error decoding index header: %v", err)
+	if err := checkResourceFileHeader(idxFile, resourceIndexMagic, idxPath); err != nil {
+		return nil, err
+	}
+	datPath := filepath.Join(l.path, pkg.String()+".dat")
+	datFile, err := os.Open(datPath)
+	if err != nil {
+		return nil, err
 	}
-	if !bytes.Equal(h.Magic[:], resourceIndexMagic[:]) {
-		log.Fatalf("wrong magic number in index file %s: %v", idxPath, h.Magic)
+	defer datFile.Close()
+	if err := checkResourceFileHeader(datFile, resourceDataMagic, datPath); err != nil {
+		return nil, err
 	}
 
 	idx := make(index)
@@ -388,11 +530,12 @@ func (l *ResourceFileLoader) loadIndex(ref ResourceRef) index {
 			if err == io.EOF {
 				break
 			}
-			log.Fatalf("error decoding index entry: %v", err)
+			return nil, fmt.Errorf("error decoding index entry: %w", err)
 		}
 		idx[entry.ID] = entry
 	}
-	return idx
+	l.indexes[pkg] = idx
+	return idx, nil
 }
 
 var (
@@ -413,6 +556,25 @@ func (h *resourceFileHeader) BinaryDecode(r io.Reader) error {
 	return BinaryDecode(r, &h.Magic, &h.Version)
 }
 
+// checkResourceFileHeader decodes the header of the resource file at the given path, and checks it
+// has the given magic number and the current format version. Files encoded with other versions of
+// the format cannot be decoded, and must be packed again.
+func checkResourceFileHeader(r io.Reader, magic [8]byte, path string) error {
+	var h resourceFileHeader
+	if err := BinaryDecode(r, &h); err != nil {
+		return fmt.Errorf("error decoding header of resource file %s: %w", path, err)
+	}
+	if !bytes.Equal(h.Magic[:], magic[:]) {
+		return fmt.Errorf("wrong magic number in resource file %s: %v", path, h.Magic)
+	}
+	if h.Version != ResourceFormatVersion {
+		return fmt.Errorf(
+			"resource file %s has format version 0x%04X, but 0x%04X is expected: repack the resources",
+			path, h.Version, ResourceFormatVersion)
+	}
+	return nil
+}
+
 type index map[ResourceID]indexEntry
 
 type indexEntry struct {
@@ -455,4 +617,29 @@ const (
 	resourceTypeScript
 	resourceTypeSound
 	resourceTypeSpriteSheet
+	resourceTypeRoomGeometry
+	resourceTypeRoom
 )
+
+func (t resourceType) String() string {
+	switch t {
+	case resourceTypeCostume:
+		return "costume"
+	case resourceTypeImage:
+		return "image"
+	case resourceTypeMusic:
+		return "music"
+	case resourceTypeScript:
+		return "script"
+	case resourceTypeSound:
+		return "sound"
+	case resourceTypeSpriteSheet:
+		return "spritesheet"
+	case resourceTypeRoomGeometry:
+		return "roomgeometry"
+	case resourceTypeRoom:
+		return "room"
+	default:
+		return "undefined"
+	}
+}
@@ -3,6 +3,8 @@ package pctk_test
 import (
 	"bytes"
 	"encoding/binary"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/apoloval/pctk"
@@ -102,3 +104,97 @@ func TestResourceEncoder_EncodeResourceWithGZip(t *testing.T) {
 	assert.Equal(t, byte(0x01), dat[0x0B])            // dat hd compression
 	assert.Equal(t, make([]byte, 14), dat[0x0C:0x1A]) // dat hd reserved
 }
+
+func TestResourceEncoder_EncodeIsReproducible(t *testing.T) {
+	encode := func() (idx, dat []byte) {
+		var idxBuf, datBuf bytes.Buffer
+		enc, err := pctk.NewResourceEncoder(&idxBuf, &datBuf)
+		require.NoError(t, err)
+		err = enc.EncodeScript(
+			pctk.ResourceID("hello"),
+			&pctk.Script{
+				Language: pctk.ScriptLua,
+				Code:     []byte("print('Hello, world!')"),
+			},
+			pctk.CompressionGzip,
+		)
+		require.NoError(t, err)
+		return idxBuf.Bytes(), datBuf.Bytes()
+	}
+
+	idx1, dat1 := encode()
+	idx2, dat2 := encode()
+	assert.Equal(t, idx1, idx2)
+	assert.Equal(t, dat1, dat2)
+}
+
+func TestResourceEncoder_EncodeRaw(t *testing.T) {
+	dir := t.TempDir()
+	script := &pctk.Script{
+		Language: pctk.ScriptLua,
+		Code:     []byte("print('Hello, world!')"),
+	}
+
+	encodeFiles := func(pkg string, encode func(enc *pctk.ResourceEncoder) error) {
+		idx, err := os.Create(filepath.Join(dir, pkg+".idx"))
+		require.NoError(t, err)
+		defer idx.Close()
+		dat, err := os.Create(filepath.Join(dir, pkg+".dat"))
+		require.NoError(t, err)
+		defer dat.Close()
+		enc, err := pctk.NewResourceEncoder(idx, dat)
+		require.NoError(t, err)
+		require.NoError(t, encode(enc))
+	}
+	encodeFiles("first", func(enc *pctk.ResourceEncoder) error {
+		return enc.EncodeScript(pctk.ResourceID("hello"), script, pctk.CompressionGzip)
+	})
+
+	loader := pctk.NewResourceFileLoader(dir)
+	raw, err := loader.LoadRaw(pctk.NewResourceRef("first", "hello"))
+	require.NoError(t, err)
+	_, err = loader.LoadRaw(pctk.NewResourceRef("first", "missing"))
+	require.Error(t, err)
+
+	encodeFiles("second", func(enc *pctk.ResourceEncoder) error {
+		return enc.EncodeRaw(pctk.ResourceID("hello"), raw)
+	})
+	loaded := loader.LoadScript(pctk.NewResourceRef("second", "hello"))
+	assert.Equal(t, script.Language, loaded.Language)
+	assert.Equal(t, script.Code, loaded.Code)
+}
+
+func TestResourceFileLoader_RejectsOtherFormatVersion(t *testing.T) {
+	dir := t.TempDir()
+	idx, err := os.Create(filepath.Join(dir, "old.idx"))
+	require.NoError(t, err)
+	dat, err := os.Create(filepath.Join(dir, "old.dat"))
+	require.NoError(t, err)
+	enc, err := pctk.NewResourceEncoder(idx, dat)
+	require.NoError(t, err)
+	require.NoError(t, enc.EncodeScript(
+		pctk.ResourceID("hello"),
+		&pctk.Script{Language: pctk.ScriptLua, Code: []byte("print('Hello, world!')")},
+		pctk.CompressionNone,
+	))
+	require.NoError(t, idx.Close())
+	require.NoError(t, dat.Close())
+
+	loader := pctk.NewResourceFileLoader(dir)
+	_, err = loader.ListResources(pctk.ResourcePackage("old"))
+	require.NoError(t, err)
+
+	// Patch the version of the index file as if it was packed with a previous version.
+	path := filepath.Join(dir, "old.idx")
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	binary.LittleEndian.PutUint16(data[8:10], pctk.ResourceFormatVersion-1)
+	require.NoError(t, os.WriteFile(path, data, 0644))
+
+	_, err = pctk.NewResourceFileLoader(dir).ListResources(pctk.ResourcePackage("old"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "repack")
+	_, err = pctk.NewResourceFileLoader(dir).LoadRaw(pctk.NewResourceRef("old", "hello"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "repack")
+}
@@ -1,13 +1,19 @@
 package main
 
 import (
-	"github.com/apoloval/pctk"
+	"log"
+
+	"github.com/apoloval/pctk/project"
 )
 
 func main() {
-	loader := pctk.NewResourceFileLoader("./")
-
-	app := pctk.New(loader, pctk.WithScreenZoom(4), pctk.WithDebugMode())
-	app.RunCommand(pctk.ScriptRun{ScriptRef: pctk.NewResourceRef("resources", "scripts/boot")})
+	man, err := project.LoadManifest(".")
+	if err != nil {
+		log.Fatal(err)
+	}
+	app, err := man.NewApp()
+	if err != nil {
+		log.Fatal(err)
+	}
 	app.Run()
 }
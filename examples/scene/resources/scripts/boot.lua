@@ -3,4 +3,4 @@ import("resources:scripts/melee.body")
 
 melee = import("resources:scripts/melee.decl")
 
-melee.melee:show()
+melee.melee:show(nil, { effect = "fade", duration = 1000 })
@@ -159,3 +159,7 @@ function pirates:talkto()
         end
     end
 end
+
+function melee.box3:enter(actor)
+    pirates:say("Where do you think you're going?")
+end
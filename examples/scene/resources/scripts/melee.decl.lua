@@ -10,6 +10,7 @@ export {
                     pos {x=80, y=117},
                 }, 
                 scale = 1,
+                farscale = 0.95,
             },
             box1 = walkbox {
                 vertices = {
@@ -19,6 +20,7 @@ export {
                     pos {x=100, y=100},
                 }, 
                 scale = 0.95,
+                farscale = 0.8,
             },
 	        box2 = walkbox {
                 vertices = { 
@@ -28,6 +30,7 @@ export {
                     pos {x=115, y=90}
                 }, 
                 scale = 0.8,
+                farscale = 0.6,
             },
 	        box3 = walkbox {
                 vertices = { 
@@ -37,6 +40,7 @@ export {
                     pos {x=128, y=82},
                 }, 
                 scale = 0.6,
+                farscale = 0.45,
                 enabled = false,
             },
 	        box4 = walkbox {
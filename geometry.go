@@ -0,0 +1,365 @@
+package pctk
+
+import (
+	"fmt"
+	"io"
+)
+
+// RoomGeometry is the geometry of a room as designed in a map editor: its background, walkboxes,
+// object hotspots and entrances. It is applied to a room when it is declared.
+type RoomGeometry struct {
+	Background *Image             // The background image, or nil if the room declares its own.
+	Layers     []LayerGeometry    // The foreground layers of the room.
+	Parallax   []ParallaxGeometry // The parallax layers of the room.
+	WalkBoxes  []WalkBoxGeometry  // The walkable areas of the room.
+	Hotspots   []HotspotGeometry  // The hotspots of the room objects.
+	Entrances  []EntranceGeometry // The named positions where actors enter the room.
+}
+
+// WalkBoxGeometry is the geometry of a walkbox.
+type WalkBoxGeometry struct {
+	ID       string
+	Vertices []Position
+	Scale    float32
+	FarScale float32 // The scale at the top edge of the walkbox, or zero if there is no gradient.
+	Mask     int     // The index of the first layer that covers actors in the walkbox, or zero if none.
+	Enabled  bool
+}
+
+// LayerGeometry is a foreground layer of a room.
+type LayerGeometry struct {
+	Image    *Image
+	Pos      Position
+	Baseline int
+}
+
+// HotspotGeometry is the geometry of an object in a room.
+type HotspotGeometry struct {
+	ID        string
+	Rect      Rectangle
+	UsePos    Position
+	UseDir    Direction
+	HasUsePos bool
+	HasUseDir bool
+}
+
+// EntranceGeometry is a named position in a room where actors enter it.
+type EntranceGeometry struct {
+	ID  string
+	Pos Position
+}
+
+// ParallaxGeometry is a parallax layer of a room.
+type ParallaxGeometry struct {
+	Image  *Image
+	Pos    Position
+	Factor float32
+	Scroll float32
+	Front  bool
+}
+
+// Apply applies the geometry to the given room. Background, layers, parallax layers and walkboxes
+// are only applied if the room does not declare its own. Hotspots complete the objects of the room with the same ID, or
+// declare new objects with no sprites nor states if there are none.
+func (g *RoomGeometry) Apply(room *Room) error {
+	if room.Background == ResourceRefNull && room.background == nil {
+		room.background = g.Background
+	}
+
+	if len(room.layers) == 0 {
+		for _, lg := range g.Layers {
+			room.DeclareLayer(&RoomLayer{Pos: lg.Pos, Baseline: lg.Baseline, image: lg.Image})
+		}
+	}
+
+	if len(room.parallax) == 0 {
+		for _, pg := range g.Parallax {
+			room.DeclareParallaxLayer(&ParallaxLayer{
+				Pos:    pg.Pos,
+				Factor: pg.Factor,
+				Scroll: pg.Scroll,
+				Front:  pg.Front,
+				image:  pg.Image,
+			})
+		}
+	}
+
+	if room.wbmatrix == nil && len(g.WalkBoxes) > 0 {
+		walkboxes := make([]*WalkBox, len(g.WalkBoxes))
+		for i, wbg := range g.WalkBoxes {
+			wb, err := wbg.build(room)
+			if err != nil {
+				return err
+			}
+			walkboxes[i] = wb
+		}
+		room.DeclareWalkBoxMatrix(walkboxes)
+	}
+
+	for _, h := range g.Hotspots {
+		obj, ok := room.objects[h.ID]
+		if !ok {
+			obj = NewObject()
+			obj.Name = h.ID
+			room.DeclareObject(h.ID, obj)
+		}
+		if obj.Hotspot.IsZero() {
+			obj.Hotspot = h.Rect
+		}
+		if h.HasUsePos {
+			obj.UsePos = h.UsePos
+		}
+		if h.HasUseDir {
+			obj.UseDir = h.UseDir
+		}
+	}
+
+	for _, e := range g.Entrances {
+		room.entrances[e.ID] = e.Pos
+	}
+	return nil
+}
+
+// BinaryEncode encodes the room geometry to a binary format. The encoded format is:
+// - bool: whether there is a background image, followed by the image if so.
+// - uint16: the number of layers, followed by each one as in LayerGeometry.BinaryEncode.
+// - uint16: the number of parallax layers, followed by each one as in
+// ParallaxGeometry.BinaryEncode.
+// - uint16: the number of walkboxes, followed by each one as in WalkBoxGeometry.BinaryEncode.
+// - uint16: the number of hotspots.
+// - for each hotspot:
+//   - string: the ID.
+//   - int16: the X, Y, width and height of the rectangle.
+//   - bool: whether it has a use position, followed by its int16 X and Y.
+//   - bool: whether it has a use direction, followed by the direction byte.
+//
+// - uint16: the number of entrances.
+// - for each entrance, the string ID and the int16 X and Y of the position.
+func (g *RoomGeometry) BinaryEncode(w io.Writer) (n int, err error) {
+	add := func(o ...any) {
+		if err != nil {
+			return
+		}
+		var nn int
+		nn, err = BinaryEncode(w, o...)
+		n += nn
+	}
+
+	add(g.Background != nil)
+	if g.Background != nil {
+		add(g.Background)
+	}
+
+	add(uint16(len(g.Layers)))
+	for _, l := range g.Layers {
+		add(l)
+	}
+
+	add(uint16(len(g.Parallax)))
+	for _, p := range g.Parallax {
+		add(p)
+	}
+
+	add(uint16(len(g.WalkBoxes)))
+	for _, wb := range g.WalkBoxes {
+		add(wb)
+	}
+
+	add(uint16(len(g.Hotspots)))
+	for _, h := range g.Hotspots {
+		add(h.ID, int16(h.Rect.Pos.X), int16(h.Rect.Pos.Y), int16(h.Rect.Size.W), int16(h.Rect.Size.H))
+		add(h.HasUsePos, int16(h.UsePos.X), int16(h.UsePos.Y))
+		add(h.HasUseDir, byte(h.UseDir))
+	}
+
+	add(uint16(len(g.Entrances)))
+	for _, e := range g.Entrances {
+		add(e.ID, int16(e.Pos.X), int16(e.Pos.Y))
+	}
+	return n, err
+}
+
+// BinaryDecode decodes the room geometry from a binary format. See RoomGeometry.BinaryEncode for
+// the format.
+func (g *RoomGeometry) BinaryDecode(r io.Reader) error {
+	var hasBackground bool
+	if err := BinaryDecode(r, &hasBackground); err != nil {
+		return err
+	}
+	g.Background = nil
+	if hasBackground {
+		g.Background = new(Image)
+		if err := BinaryDecode(r, g.Background); err != nil {
+			return err
+		}
+	}
+
+	var count uint16
+	if err := BinaryDecode(r, &count); err != nil {
+		return err
+	}
+	g.Layers = nil
+	for range count {
+		var layer LayerGeometry
+		if err := BinaryDecode(r, &layer); err != nil {
+			return err
+		}
+		g.Layers = append(g.Layers, layer)
+	}
+
+	if err := BinaryDecode(r, &count); err != nil {
+		return err
+	}
+	g.Parallax = nil
+	for range count {
+		var layer ParallaxGeometry
+		if err := BinaryDecode(r, &layer); err != nil {
+			return err
+		}
+		g.Parallax = append(g.Parallax, layer)
+	}
+
+	if err := BinaryDecode(r, &count); err != nil {
+		return err
+	}
+	g.WalkBoxes = make([]WalkBoxGeometry, count)
+	for i := range g.WalkBoxes {
+		if err := BinaryDecode(r, &g.WalkBoxes[i]); err != nil {
+			return err
+		}
+	}
+
+	if err := BinaryDecode(r, &count); err != nil {
+		return err
+	}
+	g.Hotspots = make([]HotspotGeometry, count)
+	for i := range g.Hotspots {
+		h := &g.Hotspots[i]
+		var x, y, w, hh, ux, uy int16
+		var dir byte
+		err := BinaryDecode(r, &h.ID, &x, &y, &w, &hh, &h.HasUsePos, &ux, &uy, &h.HasUseDir, &dir)
+		if err != nil {
+			return err
+		}
+		h.Rect = NewRect(int(x), int(y), int(w), int(hh))
+		h.UsePos = NewPos(int(ux), int(uy))
+		h.UseDir = Direction(dir)
+	}
+
+	if err := BinaryDecode(r, &count); err != nil {
+		return err
+	}
+	g.Entrances = make([]EntranceGeometry, count)
+	for i := range g.Entrances {
+		var x, y int16
+		if err := BinaryDecode(r, &g.Entrances[i].ID, &x, &y); err != nil {
+			return err
+		}
+		g.Entrances[i].Pos = NewPos(int(x), int(y))
+	}
+	return nil
+}
+
+// build creates the walkbox described by the geometry in the given room. Unlike NewWalkBox, it
+// returns an error if the vertices do not form a valid walkbox.
+func (wbg WalkBoxGeometry) build(room *Room) (*WalkBox, error) {
+	if len(wbg.Vertices) < 3 {
+		return nil, fmt.Errorf("walkbox %s has %d vertices, at least 3 are required", wbg.ID, len(wbg.Vertices))
+	}
+	if !IsConvexPolygon(wbg.Vertices) {
+		return nil, fmt.Errorf("walkbox %s must be a convex polygon: %v", wbg.ID, wbg.Vertices)
+	}
+	wb := NewWalkBox(wbg.ID, wbg.Vertices, wbg.Scale)
+	if wbg.FarScale != 0 {
+		wb.WithScaleGradient(wbg.FarScale)
+	}
+	wb.WithMask(wbg.Mask)
+	wb.enabled = wbg.Enabled
+	wb.room = room
+	return wb, nil
+}
+
+// BinaryEncode encodes the walkbox geometry to a binary format. The encoded format is:
+// - string: the ID.
+// - uint16: the number of vertices, followed by the int16 X and Y of each one.
+// - float32: the scale and the far scale.
+// - byte: the mask.
+// - bool: whether it is enabled.
+func (wb WalkBoxGeometry) BinaryEncode(w io.Writer) (n int, err error) {
+	add := func(o ...any) {
+		if err != nil {
+			return
+		}
+		var nn int
+		nn, err = BinaryEncode(w, o...)
+		n += nn
+	}
+
+	add(wb.ID, uint16(len(wb.Vertices)))
+	for _, v := range wb.Vertices {
+		add(int16(v.X), int16(v.Y))
+	}
+	add(wb.Scale, wb.FarScale, byte(wb.Mask), wb.Enabled)
+	return n, err
+}
+
+// BinaryDecode decodes the walkbox geometry from a binary format. See WalkBoxGeometry.BinaryEncode
+// for the format.
+func (wb *WalkBoxGeometry) BinaryDecode(r io.Reader) error {
+	var vertices uint16
+	if err := BinaryDecode(r, &wb.ID, &vertices); err != nil {
+		return err
+	}
+	wb.Vertices = make([]Position, vertices)
+	for i := range wb.Vertices {
+		var x, y int16
+		if err := BinaryDecode(r, &x, &y); err != nil {
+			return err
+		}
+		wb.Vertices[i] = NewPos(int(x), int(y))
+	}
+	var mask byte
+	if err := BinaryDecode(r, &wb.Scale, &wb.FarScale, &mask, &wb.Enabled); err != nil {
+		return err
+	}
+	wb.Mask = int(mask)
+	return nil
+}
+
+// BinaryEncode encodes the layer geometry to a binary format. The encoded format is the image,
+// followed by the int16 X and Y of its position and the int16 baseline.
+func (l LayerGeometry) BinaryEncode(w io.Writer) (int, error) {
+	return BinaryEncode(w, l.Image, int16(l.Pos.X), int16(l.Pos.Y), int16(l.Baseline))
+}
+
+// BinaryDecode decodes the layer geometry from a binary format. See LayerGeometry.BinaryEncode for
+// the format.
+func (l *LayerGeometry) BinaryDecode(r io.Reader) error {
+	var x, y, baseline int16
+	l.Image = new(Image)
+	if err := BinaryDecode(r, l.Image, &x, &y, &baseline); err != nil {
+		return err
+	}
+	l.Pos = NewPos(int(x), int(y))
+	l.Baseline = int(baseline)
+	return nil
+}
+
+// BinaryEncode encodes the parallax layer geometry to a binary format. The encoded format is the
+// image, followed by the int16 X and Y of its position, the float32 factor and scroll speed, and
+// whether it is in front of the room plane.
+func (p ParallaxGeometry) BinaryEncode(w io.Writer) (int, error) {
+	return BinaryEncode(w, p.Image, int16(p.Pos.X), int16(p.Pos.Y), p.Factor, p.Scroll, p.Front)
+}
+
+// BinaryDecode decodes the parallax layer geometry from a binary format. See
+// ParallaxGeometry.BinaryEncode for the format.
+func (p *ParallaxGeometry) BinaryDecode(r io.Reader) error {
+	var x, y int16
+	p.Image = new(Image)
+	if err := BinaryDecode(r, p.Image, &x, &y, &p.Factor, &p.Scroll, &p.Front); err != nil {
+		return err
+	}
+	p.Pos = NewPos(int(x), int(y))
+	return nil
+}
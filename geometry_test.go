@@ -0,0 +1,83 @@
+package pctk_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/apoloval/pctk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testGeometry() *pctk.RoomGeometry {
+	return &pctk.RoomGeometry{
+		WalkBoxes: []pctk.WalkBoxGeometry{
+			{
+				ID:       "floor",
+				Vertices: []pctk.Position{{X: 0, Y: 100}, {X: 320, Y: 100}, {X: 320, Y: 140}, {X: 0, Y: 140}},
+				Scale:    0.8,
+				Mask:     2,
+				Enabled:  true,
+			},
+		},
+		Hotspots: []pctk.HotspotGeometry{
+			{
+				ID:        "door",
+				Rect:      pctk.NewRect(136, 40, 48, 60),
+				UsePos:    pctk.NewPos(160, 104),
+				UseDir:    pctk.DirUp,
+				HasUsePos: true,
+				HasUseDir: true,
+			},
+			{ID: "clock", Rect: pctk.NewRect(10, 10, 5, 5)},
+		},
+		Entrances: []pctk.EntranceGeometry{
+			{ID: "west", Pos: pctk.NewPos(-10, 120)},
+		},
+	}
+}
+
+func TestRoomGeometry_BinaryEncodeDecode(t *testing.T) {
+	g := testGeometry()
+	var buf bytes.Buffer
+	n, err := g.BinaryEncode(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, buf.Len(), n)
+
+	decoded := new(pctk.RoomGeometry)
+	require.NoError(t, decoded.BinaryDecode(&buf))
+	assert.Equal(t, g, decoded)
+}
+
+func TestRoomGeometry_Apply(t *testing.T) {
+	room := pctk.NewRoom()
+	clock := pctk.NewObject()
+	clock.Name = "the clock"
+	clock.Hotspot = pctk.NewRect(1, 2, 3, 4)
+	room.DeclareObject("clock", clock)
+
+	require.NoError(t, testGeometry().Apply(room))
+
+	door := room.GetScriptField("door").UserData.(*pctk.Object)
+	assert.Equal(t, "door", door.Name)
+	assert.Equal(t, pctk.NewRect(136, 40, 48, 60), door.Hotspot)
+	assert.Equal(t, pctk.NewPos(160, 104), door.UsePos)
+	assert.Equal(t, pctk.DirUp, door.UseDir)
+
+	assert.Equal(t, "the clock", clock.Name)
+	assert.Equal(t, pctk.NewRect(1, 2, 3, 4), clock.Hotspot)
+
+	assert.NotNil(t, room.GetScriptField("floor"))
+	assert.Equal(t, pctk.NewPos(-10, 120), room.GetScriptField("west").UserData)
+}
+
+func TestRoomGeometry_ApplyInvalidWalkBox(t *testing.T) {
+	g := testGeometry()
+	g.WalkBoxes[0].Vertices = []pctk.Position{{X: 0, Y: 0}, {X: 4, Y: 0}, {X: 2, Y: 1}, {X: 4, Y: 4}}
+	err := g.Apply(pctk.NewRoom())
+	assert.ErrorContains(t, err, "walkbox floor must be a convex polygon")
+
+	g.WalkBoxes[0].Vertices = g.WalkBoxes[0].Vertices[:2]
+	err = g.Apply(pctk.NewRoom())
+	assert.ErrorContains(t, err, "walkbox floor has 2 vertices")
+}
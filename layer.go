@@ -0,0 +1,123 @@
+package pctk
+
+import (
+	"cmp"
+	"log"
+	"math"
+	"slices"
+)
+
+// RoomLayer is a foreground image of a room, like a counter, a tree or a railing, that occludes the
+// actors and objects standing behind it.
+type RoomLayer struct {
+	Image    ResourceRef // The reference to the layer image.
+	Pos      Position    // The position of the top-left corner of the layer image in the room.
+	Baseline int         // The Y coordinate over which items are drawn behind the layer, or 0 to draw it in front of all items.
+
+	image *Image
+}
+
+// Load the layer resources.
+func (l *RoomLayer) Load(res ResourceLoader) {
+	if l.image == nil {
+		l.image = res.LoadImage(l.Image)
+		if l.image == nil {
+			log.Fatalf("Layer image not found: %s", l.Image)
+		}
+	}
+}
+
+// Draw renders the layer in the viewport, tinted with the given color.
+func (l *RoomLayer) Draw(tint Color) {
+	l.image.Draw(l.Pos, tint)
+}
+
+// covers checks if the layer must be drawn in front of an item at the given position, that is,
+// when the item stands above the baseline of the layer.
+func (l *RoomLayer) covers(pos Position) bool {
+	return l.Baseline == 0 || pos.Y < l.Baseline
+}
+
+// DeclareLayer declares a foreground layer in the room. The index of a layer as referred by walkbox
+// masks is given by the order they are declared, starting at 1 for the first one.
+func (r *Room) DeclareLayer(l *RoomLayer) {
+	r.layers = append(r.layers, l)
+}
+
+// drawLayers renders the given items and the layers of the room in order. Items are drawn from top
+// to bottom, each one right before the first layer that covers it. Items not covered by any layer
+// are drawn last.
+func (r *Room) drawLayers(frame *Frame, items []RoomItem) {
+	for _, d := range r.drawOrder(items) {
+		if d.layer != nil {
+			d.layer.Draw(r.Ambient)
+		} else {
+			d.item.Draw(frame)
+		}
+	}
+}
+
+// roomDrawable is an element of the room that is drawn in the viewport: either an item or a layer.
+type roomDrawable struct {
+	item  RoomItem
+	layer *RoomLayer
+}
+
+// drawOrder returns the given items and the layers of the room in the order they must be drawn.
+// Layers are drawn from back to front, that is, by ascending baseline and the ones with no baseline
+// last, regardless of the order they were declared. Items standing in a walkbox with a mask are
+// always drawn behind the layer with that index, and thus behind all the layers drawn after it.
+func (r *Room) drawOrder(items []RoomItem) []roomDrawable {
+	layers := make([]int, len(r.layers))
+	for i := range layers {
+		layers[i] = i
+	}
+	slices.SortStableFunc(layers, func(a, b int) int {
+		return cmp.Compare(layerDepth(r.layers[a]), layerDepth(r.layers[b]))
+	})
+
+	buckets := make([][]RoomItem, len(layers)+1)
+	for _, item := range items {
+		b := len(layers)
+		mask := itemMask(item)
+		for i, index := range layers {
+			if index+1 == mask || r.layers[index].covers(item.ItemPosition()) {
+				b = i
+				break
+			}
+		}
+		buckets[b] = append(buckets[b], item)
+	}
+
+	order := make([]roomDrawable, 0, len(items)+len(layers))
+	for b, bucket := range buckets {
+		slices.SortStableFunc(bucket, func(a, b RoomItem) int {
+			return a.ItemPosition().Y - b.ItemPosition().Y
+		})
+		for _, item := range bucket {
+			order = append(order, roomDrawable{item: item})
+		}
+		if b < len(layers) {
+			order = append(order, roomDrawable{layer: r.layers[layers[b]]})
+		}
+	}
+	return order
+}
+
+// layerDepth returns the depth of the layer in the room used to sort the layers from back to front.
+// Layers with no baseline are in front of all the others.
+func layerDepth(l *RoomLayer) int {
+	if l.Baseline == 0 {
+		return math.MaxInt
+	}
+	return l.Baseline
+}
+
+// itemMask returns the mask of the walkbox where the given item stands, or 0 if it has none. Only
+// actors are masked, since objects do not stand in walkboxes.
+func itemMask(item RoomItem) int {
+	if actor, ok := item.(*Actor); ok {
+		return actor.walkBoxMask()
+	}
+	return 0
+}
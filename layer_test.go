@@ -0,0 +1,63 @@
+package pctk
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoom_drawOrder(t *testing.T) {
+	room := NewRoom()
+	counter := &RoomLayer{Baseline: 120}
+	railing := &RoomLayer{Baseline: 50}
+	room.DeclareLayer(counter)
+	room.DeclareLayer(railing)
+	floor := NewWalkBox("floor", []Position{{0, 40}, {200, 40}, {200, 140}, {0, 140}}, 1)
+	cellar := NewWalkBox("cellar", []Position{{200, 125}, {320, 125}, {320, 140}, {200, 140}}, 1).WithMask(1)
+	room.DeclareWalkBoxMatrix([]*WalkBox{floor, cellar})
+
+	place := func(x, y int) *Actor {
+		actor := NewActor("actor")
+		actor.Room = room
+		actor.pos = NewPosf(float32(x), float32(y))
+		return actor
+	}
+	behindRailing := place(100, 45)
+	betweenBoth := place(100, 80)
+	behindCounter := place(100, 110)
+	inFront := place(100, 130)
+	inCellar := place(250, 135)
+	lamp := NewObject()
+	lamp.Pos = NewPos(150, 115)
+
+	assert.Equal(t, []roomDrawable{
+		{item: behindRailing},
+		{layer: railing},
+		{item: betweenBoth},
+		{item: behindCounter},
+		{item: lamp},
+		{item: inCellar},
+		{layer: counter},
+		{item: inFront},
+	}, room.drawOrder([]RoomItem{inFront, lamp, inCellar, behindCounter, betweenBoth, behindRailing}))
+}
+
+func TestRoom_drawOrderMaskedBehindBackLayer(t *testing.T) {
+	room := NewRoom()
+	front := &RoomLayer{}
+	railing := &RoomLayer{Baseline: 50}
+	room.DeclareLayer(front)
+	room.DeclareLayer(railing)
+	floor := NewWalkBox("floor", []Position{{0, 100}, {320, 100}, {320, 140}, {0, 140}}, 1).WithMask(2)
+	room.DeclareWalkBoxMatrix([]*WalkBox{floor})
+
+	actor := NewActor("actor")
+	actor.Room = room
+	actor.pos = NewPosf(100, 120)
+
+	assert.Equal(t, []roomDrawable{
+		{item: actor},
+		{layer: railing},
+		{layer: front},
+	}, room.drawOrder([]RoomItem{actor}))
+}
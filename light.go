@@ -0,0 +1,120 @@
+package pctk
+
+import (
+	"slices"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// OpenGL blending factors and equations used to carve the lights out of the darkness of a room.
+const (
+	glZero             = 0
+	glOneMinusSrcAlpha = 0x0303
+	glFuncAdd          = 0x8006
+)
+
+// RoomLight is a circular light carried by an actor, like a flashlight or a torch, that reveals the
+// room around it when the room is dark.
+type RoomLight struct {
+	Actor  *Actor  // The actor carrying the light.
+	Radius float32 // The radius of the circle lit by the light.
+}
+
+// center returns the position of the light in the room, which is the center of the actor.
+func (l *RoomLight) center() Positionf {
+	hs := l.Actor.Hotspot()
+	return NewPosf(
+		float32(hs.Pos.X)+float32(hs.Size.W)/2,
+		float32(hs.Pos.Y)+float32(hs.Size.H)/2,
+	)
+}
+
+// LightOn turns on a light carried by the given actor, with the given radius. If the actor already
+// carries a light, its radius is changed.
+func (r *Room) LightOn(actor *Actor, radius float32) {
+	for _, l := range r.lights {
+		if l.Actor == actor {
+			l.Radius = radius
+			return
+		}
+	}
+	r.lights = append(r.lights, &RoomLight{Actor: actor, Radius: radius})
+}
+
+// LightOff turns off the light carried by the given actor, if any.
+func (r *Room) LightOff(actor *Actor) {
+	r.lights = slices.DeleteFunc(r.lights, func(l *RoomLight) bool {
+		return l.Actor == actor
+	})
+}
+
+// lightAt returns the color the actors standing at the given position are tinted with, which is
+// the ambient color of the room tinted by the light of the walkbox they stand in or the closest one.
+func (r *Room) lightAt(pos Positionf) Color {
+	light := r.Ambient
+	if r.wbmatrix == nil {
+		return light
+	}
+	id, _ := r.wbmatrix.walkBoxAt(pos)
+	if id == InvalidWalkBox {
+		return light
+	}
+	if wb := r.wbmatrix.walkBoxes[id]; wb.light != Blank {
+		light = tintColor(light, wb.light)
+	}
+	return light
+}
+
+// renderLightMap renders the darkness of the room with its lights carved out into the light map.
+// It must be called out of the camera mode, since the light map covers the whole room.
+func (r *Room) renderLightMap() {
+	if r.Darkness <= 0 || r.background == nil {
+		return
+	}
+	w, h := int32(r.background.Width()), int32(r.background.Height())
+	if !rl.IsRenderTextureReady(r.lightmap) || r.lightmap.Texture.Width != w || r.lightmap.Texture.Height != h {
+		if rl.IsRenderTextureReady(r.lightmap) {
+			rl.UnloadRenderTexture(r.lightmap)
+		}
+		r.lightmap = rl.LoadRenderTexture(w, h)
+	}
+
+	rl.BeginTextureMode(r.lightmap)
+	rl.ClearBackground(rl.Fade(Black, min(1, r.Darkness)))
+
+	// Lights reduce the opacity of the darkness, more at their centers than at their edges.
+	rl.SetBlendFactors(glZero, glOneMinusSrcAlpha, glFuncAdd)
+	rl.BeginBlendMode(rl.BlendCustom)
+	for _, l := range r.lights {
+		if l.Actor.Room != r || l.Radius <= 0 {
+			continue
+		}
+		c := l.center()
+		rl.DrawCircleGradient(int32(c.X), int32(c.Y), l.Radius, White, Blank)
+	}
+	rl.EndBlendMode()
+	rl.EndTextureMode()
+}
+
+// drawLightMap draws the darkness of the room over it, if any.
+func (r *Room) drawLightMap() {
+	if r.Darkness <= 0 || !rl.IsRenderTextureReady(r.lightmap) {
+		return
+	}
+	tex := r.lightmap.Texture
+	src := rl.NewRectangle(0, 0, float32(tex.Width), -float32(tex.Height))
+	rl.DrawTextureRec(tex, src, rl.NewVector2(0, 0), White)
+}
+
+// tintColor returns the color c tinted with the given color, multiplying their components.
+func tintColor(c, tint Color) Color {
+	mul := func(a, b uint8) uint8 {
+		return uint8(uint16(a) * uint16(b) / 0xFF)
+	}
+	return Color{
+		R: mul(c.R, tint.R),
+		G: mul(c.G, tint.G),
+		B: mul(c.B, tint.B),
+		A: mul(c.A, tint.A),
+	}
+}
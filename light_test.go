@@ -0,0 +1,44 @@
+package pctk
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTintColor(t *testing.T) {
+	assert.Equal(t, Red, tintColor(Red, White))
+	assert.Equal(t, Black, tintColor(Red, Black))
+	assert.Equal(t, Color{R: 0x71, G: 0x00, B: 0x00, A: 0xFF}, tintColor(Red, LightGray))
+	assert.Equal(t, Blank, tintColor(White, Blank))
+}
+
+func TestRoom_lightAt(t *testing.T) {
+	room := NewRoom()
+	floor := NewWalkBox("floor", []Position{{0, 100}, {200, 100}, {200, 140}, {0, 140}}, 1)
+	lamp := NewWalkBox("lamp", []Position{{200, 100}, {320, 100}, {320, 140}, {200, 140}}, 1).WithLight(Yellow)
+	room.DeclareWalkBoxMatrix([]*WalkBox{floor, lamp})
+
+	assert.Equal(t, White, room.lightAt(NewPosf(100, 120)))
+	assert.Equal(t, Yellow, room.lightAt(NewPosf(250, 120)))
+
+	room.Ambient = LightGray
+	assert.Equal(t, LightGray, room.lightAt(NewPosf(100, 120)))
+	assert.Equal(t, tintColor(LightGray, Yellow), room.lightAt(NewPosf(250, 120)))
+}
+
+func TestRoom_LightOn(t *testing.T) {
+	room := NewRoom()
+	guybrush, lechuck := NewActor("guybrush"), NewActor("lechuck")
+
+	room.LightOn(guybrush, 40)
+	room.LightOn(lechuck, 20)
+	room.LightOn(guybrush, 60)
+	assert.Equal(t, []*RoomLight{
+		{Actor: guybrush, Radius: 60},
+		{Actor: lechuck, Radius: 20},
+	}, room.lights)
+
+	room.LightOff(guybrush)
+	assert.Equal(t, []*RoomLight{{Actor: lechuck, Radius: 20}}, room.lights)
+}
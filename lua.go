@@ -185,6 +185,38 @@ func (l *LuaInterpreter) DeclareActorType() {
 		l.app.RunCommand(cmd).Wait()
 		return 0
 	})
+	l.DeclareEntityMethod(ScriptEntityActor, "play", func(l *LuaInterpreter) int {
+		var cmd ActorPlay
+		cmd.Actor = l.CheckEntity(1, ScriptEntityActor).(*Actor)
+		cmd.Action = lua.CheckString(l.State, 2)
+		l.WithOptionalField(3, "dir", func() {
+			dir := l.CheckEntity(-1, ScriptEntityDir).(Direction)
+			cmd.Direction = &dir
+		})
+		l.WithOptionalField(3, "loop", func() {
+			cmd.Loop = l.ToBoolean(-1)
+		})
+		l.WithOptionalField(3, "connect", func() {
+			cmd.UntilConnect = l.ToBoolean(-1)
+		})
+		done := l.app.RunCommand(cmd)
+		l.PushEntity(ScriptEntityFuture, done)
+		return 1
+	})
+	l.DeclareEntityMethod(ScriptEntityActor, "onframeevent", func(l *LuaInterpreter) int {
+		var cmd ActorOnFrameEvent
+		cmd.Actor = l.CheckEntity(1, ScriptEntityActor).(*Actor)
+		cmd.Event = lua.CheckString(l.State, 2)
+		lua.CheckType(l.State, 3, lua.TypeFunction)
+		l.PushValue(3)
+		cmd.Callback = &ScriptCallback{
+			Name:   cmd.Event,
+			Script: l.script,
+			ID:     l.RegisterCallback(1),
+		}
+		l.app.RunCommand(cmd).Wait()
+		return 0
+	})
 	l.DeclareEntityMethod(ScriptEntityActor, "say", func(l *LuaInterpreter) int {
 		var cmd ActorSpeak
 		cmd.Actor = l.CheckEntity(1, ScriptEntityActor).(*Actor)
@@ -259,6 +291,8 @@ func (l *LuaInterpreter) DeclareActorType() {
 
 // DeclareAnimType declares the type of an Animation in the Lua interpreter.
 func (l *LuaInterpreter) DeclareAnimType() {
+	l.DeclareReferenceType()
+
 	if l.DeclareEntityType(ScriptEntityAnimation) {
 		return
 	}
@@ -282,6 +316,25 @@ func (l *LuaInterpreter) DeclareAnimType() {
 			row,
 			cols...,
 		)
+		l.WithOptionalField(1, "events", func() {
+			l.WithEachArrayItem(-1, func(idx int) {
+				frame := l.CheckFieldInteger(-1, "frame")
+				if frame < 0 || frame >= anim.Frames() {
+					lua.Errorf(l.State, "invalid frame %d for event %d of animation", frame, idx)
+				}
+				var ev AnimationEvent
+				l.WithOptionalField(-1, "event", func() {
+					ev.Name = lua.CheckString(l.State, -1)
+				})
+				l.WithOptionalField(-1, "sound", func() {
+					ev.Sound = l.CheckEntity(-1, ScriptEntityRef).(ResourceRef)
+				})
+				l.WithOptionalField(-1, "connect", func() {
+					ev.Connect = l.ToBoolean(-1)
+				})
+				anim.AddEvent(frame, ev)
+			})
+		})
 		l.PushEntity(ScriptEntityAnimation, anim)
 		return 1
 	})
@@ -798,6 +851,8 @@ func (l *LuaInterpreter) DeclareObjectType() {
 			switch key {
 			case "class":
 				obj.Class = l.CheckEntity(-1, ScriptEntityClass).(ObjectClass)
+			case "exit":
+				obj.Exit = l.checkObjectExit(-1)
 			case "hotspot":
 				obj.Hotspot = l.CheckEntity(-1, ScriptEntityRect).(Rectangle)
 			case "name":
@@ -864,6 +919,15 @@ func (l *LuaInterpreter) DeclareObjectType() {
 		l.PushEntity(ScriptEntityPos, obj.Pos)
 		return 1
 	})
+	l.DeclareEntitySetter(ScriptEntityObject, "exit", func(l *LuaInterpreter) int {
+		obj := l.CheckEntity(1, ScriptEntityObject).(*Object)
+		var exit *ObjectExit
+		if !l.IsNil(2) {
+			exit = l.checkObjectExit(2)
+		}
+		l.app.RunCommand(ObjectSetExit(obj, exit)).Wait()
+		return 0
+	})
 	l.DeclareEntityMethod(ScriptEntityObject, "classon", func(l *LuaInterpreter) int {
 		obj := l.CheckEntity(1, ScriptEntityObject).(*Object)
 		class := l.CheckEntity(2, ScriptEntityClass).(ObjectClass)
@@ -883,6 +947,24 @@ func (l *LuaInterpreter) DeclareObjectType() {
 	})
 }
 
+// checkObjectExit checks if the value at the given index is a table describing an exit, with the
+// room it leads to, the tag of the entrance object in that room and optionally the transition, and
+// returns it.
+func (l *LuaInterpreter) checkObjectExit(index int) *ObjectExit {
+	index = l.AbsIndex(index)
+	exit := &ObjectExit{}
+	l.WithField(index, "room", func() {
+		exit.Room = l.CheckEntity(-1, ScriptEntityRoom).(*Room)
+	})
+	l.WithField(index, "entrance", func() {
+		exit.Entrance = lua.CheckString(l.State, -1)
+	})
+	l.WithOptionalField(index, "transition", func() {
+		exit.Transition = l.checkTransition(-1)
+	})
+	return exit
+}
+
 // DeclareObjectStateType declares the type of an ObjectState in the Lua interpreter.
 func (l *LuaInterpreter) DeclareObjectStateType() {
 	l.DeclareAnimType()
@@ -1014,19 +1096,90 @@ func (l *LuaInterpreter) DeclareReferenceType() {
 func (l *LuaInterpreter) DeclareRoomType() {
 	l.DeclareClassType()
 	l.DeclareObjectType()
+	l.DeclareColorType()
 	l.DeclareReferenceType()
+	l.DeclareSizeType()
+	l.DeclareSoundType()
+	l.DeclareTimerType()
+	l.DeclareTriggerType()
 
 	if l.DeclareEntityType(ScriptEntityRoom) {
 		return
 	}
 	l.DeclareEntityConstructor(ScriptEntityRoom, "room",
 		func(l *LuaInterpreter) int {
+			if l.EntityTypeOf(1) == ScriptEntityRef {
+				return l.loadRoom(l.CheckEntity(1, ScriptEntityRef).(ResourceRef))
+			}
+
 			room := NewRoom()
 			objects := make(map[string]*Object)
+			geometry := ResourceRefNull
 			l.WithEachTableItem(1, func(key string) {
 				switch key {
+				case "ambient":
+					room.Ambient = l.CheckEntity(-1, ScriptEntityColor).(Color)
 				case "background":
 					room.Background = l.CheckEntity(-1, ScriptEntityRef).(ResourceRef)
+				case "darkness":
+					room.Darkness = l.checkDarkness(-1)
+				case "geometry":
+					geometry = l.CheckEntity(-1, ScriptEntityRef).(ResourceRef)
+				case "zoom":
+					room.Zoom = float32(lua.CheckNumber(l.State, -1))
+					if room.Zoom <= 0 {
+						lua.ArgumentError(l.State, 1, "room zoom must be positive")
+					}
+				case "layers":
+					l.WithEachArrayItem(-1, func(idx int) {
+						layer := &RoomLayer{
+							Image: l.CheckFieldEntity(-1, "image", ScriptEntityRef).(ResourceRef),
+						}
+						l.WithOptionalField(-1, "pos", func() {
+							layer.Pos = l.CheckEntity(-1, ScriptEntityPos).(Position)
+						})
+						l.WithOptionalField(-1, "baseline", func() {
+							layer.Baseline = int(lua.CheckInteger(l.State, -1))
+						})
+						room.DeclareLayer(layer)
+					})
+				case "parallax":
+					l.WithEachArrayItem(-1, func(idx int) {
+						layer := &ParallaxLayer{
+							Image:  l.CheckFieldEntity(-1, "image", ScriptEntityRef).(ResourceRef),
+							Factor: 1,
+						}
+						l.WithOptionalField(-1, "pos", func() {
+							layer.Pos = l.CheckEntity(-1, ScriptEntityPos).(Position)
+						})
+						l.WithOptionalField(-1, "factor", func() {
+							layer.Factor = float32(lua.CheckNumber(l.State, -1))
+						})
+						l.WithOptionalField(-1, "scroll", func() {
+							layer.Scroll = float32(lua.CheckNumber(l.State, -1))
+						})
+						l.WithOptionalField(-1, "front", func() {
+							layer.Front = l.State.ToBoolean(-1)
+						})
+						room.DeclareParallaxLayer(layer)
+					})
+				case "sounds":
+					l.WithEachTableItem(-1, func(name string) {
+						e := NewSoundEmitter(
+							l.CheckFieldEntity(-1, "sound", ScriptEntitySound).(*Sound),
+							l.CheckFieldEntity(-1, "pos", ScriptEntityPos).(Position),
+						)
+						l.WithOptionalField(-1, "radius", func() {
+							e.Radius = lua.CheckInteger(l.State, -1)
+						})
+						l.WithOptionalField(-1, "volume", func() {
+							e.Volume = float32(lua.CheckNumber(l.State, -1))
+						})
+						l.WithOptionalField(-1, "enabled", func() {
+							e.Enabled = l.State.ToBoolean(-1)
+						})
+						room.DeclareSoundEmitter(name, e)
+					})
 				case "walkboxes":
 					var walkboxes []*WalkBox
 					l.WithEachTableItem(-1, func(k string) {
@@ -1043,6 +1196,8 @@ func (l *LuaInterpreter) DeclareRoomType() {
 						obj := l.CheckEntity(-1, ScriptEntityObject).(*Object)
 						room.DeclareObject(key, obj)
 						objects[key] = obj
+					case ScriptEntityTrigger:
+						room.DeclareTrigger(key, l.CheckEntity(-1, ScriptEntityTrigger).(*Trigger))
 					default:
 						lua.ArgumentError(l.State, -1, fmt.Sprintf(
 							"unexpected field '%s' in room constructor", key))
@@ -1050,6 +1205,16 @@ func (l *LuaInterpreter) DeclareRoomType() {
 				}
 			})
 
+			if geometry != ResourceRefNull {
+				g := l.app.res.LoadRoomGeometry(geometry)
+				if g == nil {
+					lua.Errorf(l.State, "room geometry not found: %s", geometry)
+				}
+				if err := g.Apply(room); err != nil {
+					lua.Errorf(l.State, "error applying room geometry: %s", err)
+				}
+			}
+
 			err := l.app.DeclareRoom(room)
 			if err != nil {
 				lua.Errorf(l.State, "error declaring room: %s", err)
@@ -1059,11 +1224,70 @@ func (l *LuaInterpreter) DeclareRoomType() {
 			return 1
 		},
 	)
+	l.DeclareEntityGetter(ScriptEntityRoom, "ambient", func(l *LuaInterpreter) int {
+		room := l.CheckEntity(1, ScriptEntityRoom).(*Room)
+		l.PushEntity(ScriptEntityColor, room.Ambient)
+		return 1
+	})
+	l.DeclareEntitySetter(ScriptEntityRoom, "ambient", func(l *LuaInterpreter) int {
+		room := l.CheckEntity(1, ScriptEntityRoom).(*Room)
+		color := l.CheckEntity(2, ScriptEntityColor).(Color)
+		l.app.RunCommand(RoomAmbient(room, color)).Wait()
+		return 0
+	})
 	l.DeclareEntityGetter(ScriptEntityRoom, "background", func(l *LuaInterpreter) int {
 		room := l.CheckEntity(1, ScriptEntityRoom).(*Room)
 		l.PushEntity(ScriptEntityRef, room.Background)
 		return 1
 	})
+	l.DeclareEntityGetter(ScriptEntityRoom, "darkness", func(l *LuaInterpreter) int {
+		room := l.CheckEntity(1, ScriptEntityRoom).(*Room)
+		l.PushNumber(float64(room.Darkness))
+		return 1
+	})
+	l.DeclareEntitySetter(ScriptEntityRoom, "darkness", func(l *LuaInterpreter) int {
+		room := l.CheckEntity(1, ScriptEntityRoom).(*Room)
+		darkness := l.checkDarkness(2)
+		l.app.RunCommand(RoomDarkness(room, darkness)).Wait()
+		return 0
+	})
+	l.DeclareEntityMethod(ScriptEntityRoom, "after", func(l *LuaInterpreter) int {
+		return l.startRoomTimer(false)
+	})
+	l.DeclareEntityMethod(ScriptEntityRoom, "every", func(l *LuaInterpreter) int {
+		return l.startRoomTimer(true)
+	})
+	l.DeclareEntityMethod(ScriptEntityRoom, "soundon", func(l *LuaInterpreter) int {
+		room := l.CheckEntity(1, ScriptEntityRoom).(*Room)
+		name := lua.CheckString(l.State, 2)
+		_, err := l.app.RunCommand(SoundEmitterEnable(room, name, true)).Wait()
+		if err != nil {
+			lua.Errorf(l.State, "error enabling sound: %s", err.Error())
+		}
+		return 0
+	})
+	l.DeclareEntityMethod(ScriptEntityRoom, "soundoff", func(l *LuaInterpreter) int {
+		room := l.CheckEntity(1, ScriptEntityRoom).(*Room)
+		name := lua.CheckString(l.State, 2)
+		_, err := l.app.RunCommand(SoundEmitterEnable(room, name, false)).Wait()
+		if err != nil {
+			lua.Errorf(l.State, "error disabling sound: %s", err.Error())
+		}
+		return 0
+	})
+	l.DeclareEntityMethod(ScriptEntityRoom, "lighton", func(l *LuaInterpreter) int {
+		room := l.CheckEntity(1, ScriptEntityRoom).(*Room)
+		actor := l.CheckEntity(2, ScriptEntityActor).(*Actor)
+		radius := lua.CheckNumber(l.State, 3)
+		l.app.RunCommand(RoomLightOn(room, actor, float32(radius))).Wait()
+		return 0
+	})
+	l.DeclareEntityMethod(ScriptEntityRoom, "lightoff", func(l *LuaInterpreter) int {
+		room := l.CheckEntity(1, ScriptEntityRoom).(*Room)
+		actor := l.CheckEntity(2, ScriptEntityActor).(*Actor)
+		l.app.RunCommand(RoomLightOff(room, actor)).Wait()
+		return 0
+	})
 	l.DeclareEntityMethod(ScriptEntityRoom, "camfollow", func(l *LuaInterpreter) int {
 		l.CheckEntity(1, ScriptEntityRoom)
 		actor := l.CheckEntity(2, ScriptEntityActor).(*Actor)
@@ -1076,14 +1300,56 @@ func (l *LuaInterpreter) DeclareRoomType() {
 	})
 	l.DeclareEntityMethod(ScriptEntityRoom, "camto", func(l *LuaInterpreter) int {
 		l.CheckEntity(1, ScriptEntityRoom)
-		pos := lua.CheckInteger(l.State, 2)
 		// TODO: hack obtaining viewport
-		_, err := l.app.RunCommand(RoomCameraTo(&l.app.viewport, pos)).Wait()
+		var cmd CommandFunc
+		if l.EntityTypeOf(2) == ScriptEntityPos {
+			cmd = RoomCameraTo(&l.app.viewport, l.CheckEntity(2, ScriptEntityPos).(Position))
+		} else {
+			cmd = RoomCameraToX(&l.app.viewport, lua.CheckInteger(l.State, 2))
+		}
+		_, err := l.app.RunCommand(cmd).Wait()
 		if err != nil {
 			lua.Errorf(l.State, "error moving camera to position: %s", err.Error())
 		}
 		return 0
 	})
+	l.DeclareEntityMethod(ScriptEntityRoom, "campan", func(l *LuaInterpreter) int {
+		l.CheckEntity(1, ScriptEntityRoom)
+		pos := l.CheckEntity(2, ScriptEntityPos).(Position)
+		duration := time.Duration(lua.CheckInteger(l.State, 3)) * time.Millisecond
+		// TODO: hack obtaining viewport
+		done := l.app.RunCommand(RoomCameraPan(&l.app.viewport, pos, duration))
+		l.PushEntity(ScriptEntityFuture, done)
+		return 1
+	})
+	l.DeclareEntityMethod(ScriptEntityRoom, "camzoom", func(l *LuaInterpreter) int {
+		l.CheckEntity(1, ScriptEntityRoom)
+		zoom := float32(lua.CheckNumber(l.State, 2))
+		duration := time.Duration(lua.OptInteger(l.State, 3, 0)) * time.Millisecond
+		// TODO: hack obtaining viewport
+		done := l.app.RunCommand(RoomCameraZoom(&l.app.viewport, zoom, duration))
+		l.PushEntity(ScriptEntityFuture, done)
+		return 1
+	})
+	l.DeclareEntityMethod(ScriptEntityRoom, "camshake", func(l *LuaInterpreter) int {
+		l.CheckEntity(1, ScriptEntityRoom)
+		intensity := float32(lua.CheckNumber(l.State, 2))
+		duration := time.Duration(lua.CheckInteger(l.State, 3)) * time.Millisecond
+		// TODO: hack obtaining viewport
+		done := l.app.RunCommand(RoomCameraShake(&l.app.viewport, intensity, duration))
+		l.PushEntity(ScriptEntityFuture, done)
+		return 1
+	})
+	l.DeclareEntityMethod(ScriptEntityRoom, "camdeadzone", func(l *LuaInterpreter) int {
+		l.CheckEntity(1, ScriptEntityRoom)
+		size := l.CheckEntity(2, ScriptEntitySize).(Size)
+		// TODO: hack obtaining viewport
+		_, err := l.app.RunCommand(RoomCameraDeadZone(&l.app.viewport, size)).Wait()
+		if err != nil {
+			lua.Errorf(l.State, "error setting camera dead zone: %s", err.Error())
+		}
+		return 0
+	})
 	l.DeclareEntityMethod(ScriptEntityRoom, "camleft", func(l *LuaInterpreter) int {
 		l.CheckEntity(1, ScriptEntityRoom)
 		// TODO: hack obtaining viewport
@@ -1109,9 +1375,133 @@ func (l *LuaInterpreter) DeclareRoomType() {
 		if !l.IsNil(2) {
 			cmd.Entrance = l.CheckEntity(2, ScriptEntityObject).(*Object)
 		}
-		l.app.RunCommand(cmd)
-		return 0
+		if !l.IsNoneOrNil(3) {
+			cmd.Transition = l.checkTransition(3)
+		}
+		done := l.app.RunCommand(cmd)
+		l.PushEntity(ScriptEntityFuture, done)
+		return 1
+	})
+	l.DeclareEntityMethod(ScriptEntityRoom, "fadeout", func(l *LuaInterpreter) int {
+		l.CheckEntity(1, ScriptEntityRoom)
+		effect := l.checkTransitionEffect(2)
+		duration := time.Duration(lua.CheckInteger(l.State, 3)) * time.Millisecond
+		var center *Actor
+		if !l.IsNoneOrNil(4) {
+			center = l.CheckEntity(4, ScriptEntityActor).(*Actor)
+		}
+		// TODO: hack obtaining viewport
+		done := l.app.RunCommand(RoomTransitionOut(&l.app.viewport, effect, duration, center))
+		l.PushEntity(ScriptEntityFuture, done)
+		return 1
+	})
+	l.DeclareEntityMethod(ScriptEntityRoom, "fadein", func(l *LuaInterpreter) int {
+		l.CheckEntity(1, ScriptEntityRoom)
+		effect := l.checkTransitionEffect(2)
+		duration := time.Duration(lua.CheckInteger(l.State, 3)) * time.Millisecond
+		var center *Actor
+		if !l.IsNoneOrNil(4) {
+			center = l.CheckEntity(4, ScriptEntityActor).(*Actor)
+		}
+		// TODO: hack obtaining viewport
+		done := l.app.RunCommand(RoomTransitionIn(&l.app.viewport, effect, duration, center))
+		l.PushEntity(ScriptEntityFuture, done)
+		return 1
 	})
+	l.DeclareEntityMethod(ScriptEntityRoom, "tint", func(l *LuaInterpreter) int {
+		l.CheckEntity(1, ScriptEntityRoom)
+		color := l.CheckEntity(2, ScriptEntityColor).(Color)
+		duration := time.Duration(lua.OptInteger(l.State, 3, 0)) * time.Millisecond
+		// TODO: hack obtaining viewport
+		done := l.app.RunCommand(RoomTint(&l.app.viewport, color, duration))
+		l.PushEntity(ScriptEntityFuture, done)
+		return 1
+	})
+}
+
+// startRoomTimer starts a timer in the room at index 1 that expires after the milliseconds at index
+// 2, calling the function at index 3 once or repeatedly, and pushes the timer into the stack.
+func (l *LuaInterpreter) startRoomTimer(repeat bool) int {
+	room := l.CheckEntity(1, ScriptEntityRoom).(*Room)
+	millis := lua.CheckInteger(l.State, 2)
+	if millis <= 0 {
+		lua.ArgumentError(l.State, 2, "timer interval must be positive")
+	}
+	lua.CheckType(l.State, 3, lua.TypeFunction)
+	l.PushValue(3)
+	t := &RoomTimer{
+		Callback: &ScriptCallback{
+			Name:   "timer",
+			Script: l.script,
+			ID:     l.RegisterCallback(1),
+		},
+		Interval: time.Duration(millis) * time.Millisecond,
+		Repeat:   repeat,
+	}
+	l.app.RunCommand(RoomStartTimer(room, t)).Wait()
+	l.PushEntity(ScriptEntityTimer, t)
+	return 1
+}
+
+// checkDarkness checks if the value at the given index is a darkness level between 0 and 1, and
+// returns it.
+func (l *LuaInterpreter) checkDarkness(index int) float32 {
+	darkness := lua.CheckNumber(l.State, index)
+	if darkness < 0 || darkness > 1 {
+		lua.ArgumentError(l.State, index, "darkness must be between 0 and 1")
+	}
+	return float32(darkness)
+}
+
+// checkTransition checks if the value at the given index is a table describing a room transition,
+// with the effect name, the duration in milliseconds and optionally the actor the effect is
+// centered on, and returns it.
+func (l *LuaInterpreter) checkTransition(index int) Transition {
+	var tr Transition
+	l.WithField(index, "effect", func() {
+		tr.Effect = l.checkTransitionEffect(-1)
+	})
+	l.WithOptionalField(index, "duration", func() {
+		tr.Duration = time.Duration(lua.CheckInteger(l.State, -1)) * time.Millisecond
+	})
+	l.WithOptionalField(index, "center", func() {
+		tr.Center = l.CheckEntity(-1, ScriptEntityActor).(*Actor)
+	})
+	return tr
+}
+
+// checkTransitionEffect checks if the value at the given index is the name of a transition effect,
+// and returns it.
+func (l *LuaInterpreter) checkTransitionEffect(index int) TransitionEffect {
+	effect, err := ParseTransitionEffect(lua.CheckString(l.State, index))
+	if err != nil {
+		lua.ArgumentError(l.State, index, err.Error())
+	}
+	return effect
+}
+
+// loadRoom builds the room described by the given resource, declares it along with the actors
+// placed in it, and pushes it into the stack.
+func (l *LuaInterpreter) loadRoom(ref ResourceRef) int {
+	desc := l.app.res.LoadRoom(ref)
+	if desc == nil {
+		lua.Errorf(l.State, "room not found: %s", ref)
+	}
+	room, err := desc.Build()
+	if err != nil {
+		lua.Errorf(l.State, "error building room %s: %s", ref, err)
+	}
+	for _, actor := range room.actors {
+		if err := l.app.DeclareActor(actor); err != nil {
+			lua.Errorf(l.State, "error declaring actor: %s", err)
+		}
+	}
+	if err := l.app.DeclareRoom(room); err != nil {
+		lua.Errorf(l.State, "error declaring room: %s", err)
+	}
+
+	l.PushEntity(ScriptEntityRoom, room)
+	return 1
 }
 
 // DeclareSentenceChoiceType declares the type of a SentenceChoice in the Lua interpreter.
@@ -1183,6 +1573,7 @@ func (l *LuaInterpreter) DeclareSizeType() {
 
 // DeclareSoundType declares the type of a Sound in the Lua interpreter.
 func (l *LuaInterpreter) DeclareSoundType() {
+	l.DeclarePositionType()
 	l.DeclareReferenceType()
 
 	if l.DeclareEntityType(ScriptEntitySound) {
@@ -1204,8 +1595,21 @@ func (l *LuaInterpreter) DeclareSoundType() {
 		return 1
 	})
 	l.DeclareEntityMethod(ScriptEntitySound, "play", func(l *LuaInterpreter) int {
-		sound := l.CheckEntity(1, ScriptEntitySound).(*Sound)
-		l.app.RunCommand(SoundPlay{Sound: sound})
+		cmd := SoundPlay{
+			Sound: l.CheckEntity(1, ScriptEntitySound).(*Sound),
+		}
+		if !l.IsNoneOrNil(2) {
+			switch l.EntityTypeOf(2) {
+			case ScriptEntityActor:
+				cmd.From = l.CheckEntity(2, ScriptEntityActor).(*Actor)
+			case ScriptEntityObject:
+				cmd.From = l.CheckEntity(2, ScriptEntityObject).(*Object)
+			default:
+				pos := l.CheckEntity(2, ScriptEntityPos).(Position)
+				cmd.Pos = &pos
+			}
+		}
+		l.app.RunCommand(cmd)
 		return 0
 	})
 	l.DeclareEntityMethod(ScriptEntitySound, "stop", func(l *LuaInterpreter) int {
@@ -1228,37 +1632,103 @@ func (l *LuaInterpreter) DeclareUtilityFunctions() {
 		return 0
 	})
 	l.SetGlobal("sleep")
+
+	if l.app != nil {
+		l.PushString(l.app.language)
+		l.SetGlobal("LANGUAGE")
+	}
+}
+
+// DeclareTimerType declares the type of a RoomTimer in the Lua interpreter.
+func (l *LuaInterpreter) DeclareTimerType() {
+	if l.DeclareEntityType(ScriptEntityTimer) {
+		return
+	}
+	l.DeclareEntityMethod(ScriptEntityTimer, "cancel", func(l *LuaInterpreter) int {
+		t := l.CheckEntity(1, ScriptEntityTimer).(*RoomTimer)
+		l.app.RunCommand(RoomCancelTimer(t)).Wait()
+		return 0
+	})
+}
+
+// DeclareTriggerType declares the type of a Trigger in the Lua interpreter.
+func (l *LuaInterpreter) DeclareTriggerType() {
+	l.DeclarePositionType()
+	l.DeclareRectType()
+	if l.DeclareEntityType(ScriptEntityTrigger) {
+		return
+	}
+	l.DeclareEntityConstructor(ScriptEntityTrigger, "trigger", func(l *LuaInterpreter) int {
+		var trigger *Trigger
+		l.WithOptionalField(1, "rect", func() {
+			trigger = NewTriggerRect(l.CheckEntity(-1, ScriptEntityRect).(Rectangle))
+		})
+		l.WithOptionalField(1, "vertices", func() {
+			var vertices []Position
+			l.WithEachArrayItem(-1, func(idx int) {
+				vertices = append(vertices, l.CheckEntity(-1, ScriptEntityPos).(Position))
+			})
+			if len(vertices) < 3 {
+				lua.ArgumentError(l.State, 1, "trigger must have at least 3 vertices")
+			}
+			trigger = NewTrigger(vertices)
+		})
+		if trigger == nil {
+			lua.ArgumentError(l.State, 1, "trigger must have either a rect or vertices")
+		}
+		l.PushEntity(ScriptEntityTrigger, trigger)
+		return 1
+	})
 }
 
 // DeclareWalkBoxType declares the type of a Walkbox in the Lua interpreter.
 func (l *LuaInterpreter) DeclareWalkBoxType() {
+	l.DeclareColorType()
 	l.DeclarePositionType()
 	if l.DeclareEntityType(ScriptEntityWalkBox) {
 		return
 	}
 	l.DeclareEntityConstructor(ScriptEntityWalkBox, "walkbox", func(l *LuaInterpreter) int {
-		var vertices [4]Position
+		var vertices []Position
 		scale := 1.0
 		l.WithField(1, "vertices", func() {
 			l.WithEachArrayItem(-1, func(idx int) {
-				if idx > 4 {
-					lua.ArgumentError(l.State, 1, "walkbox must have 4 vertices")
-				}
-				vertices[idx-1] = l.CheckEntity(-1, ScriptEntityPos).(Position)
+				vertices = append(vertices, l.CheckEntity(-1, ScriptEntityPos).(Position))
 			})
 		})
+		if len(vertices) < 3 {
+			lua.ArgumentError(l.State, 1, "walkbox must have at least 3 vertices")
+		}
 		l.WithOptionalField(1, "scale", func() {
 			scale = lua.CheckNumber(l.State, -1)
 		})
 
 		walkbox := NewWalkBox("", vertices, float32(scale))
 
+		l.WithOptionalField(1, "farscale", func() {
+			walkbox.WithScaleGradient(float32(lua.CheckNumber(l.State, -1)))
+		})
+		l.WithOptionalField(1, "mask", func() {
+			walkbox.WithMask(lua.CheckInteger(l.State, -1))
+		})
 		l.WithOptionalField(1, "enabled", func() {
 			walkbox.enabled = l.State.ToBoolean(-1)
 		})
+		l.WithOptionalField(1, "light", func() {
+			walkbox.WithLight(l.CheckEntity(-1, ScriptEntityColor).(Color))
+		})
 		l.PushEntity(ScriptEntityWalkBox, walkbox)
 		return 1
 	})
+	l.DeclareEntitySetter(ScriptEntityWalkBox, "light", func(l *LuaInterpreter) int {
+		w := l.CheckEntity(1, ScriptEntityWalkBox).(*WalkBox)
+		var c Color
+		if !l.IsNil(2) {
+			c = l.CheckEntity(2, ScriptEntityColor).(Color)
+		}
+		l.app.RunCommand(WalkBoxLight(w, c)).Wait()
+		return 0
+	})
 	l.DeclareEntityMethod(ScriptEntityWalkBox, "enable", func(l *LuaInterpreter) int {
 		w := l.CheckEntity(1, ScriptEntityWalkBox).(*WalkBox)
 		_, err := l.app.RunCommand(EnableWalkBox(w)).Wait()
@@ -1300,8 +1770,13 @@ func (l *LuaInterpreter) PushFunction(f LuaFunction) {
 }
 
 // PushEntity pushes an entity into the stack. The entity must be a user data. This function will
-// configure the metatable of the entity to the one of the given entity type.
+// configure the metatable of the entity to the one of the given entity type. Plain numbers are
+// pushed as is.
 func (l *LuaInterpreter) PushEntity(typ ScriptEntityType, obj any) {
+	if typ == ScriptEntityNumber {
+		l.PushNumber(obj.(float64))
+		return
+	}
 	l.NewTable()
 
 	// Configure the user data of the entity table.
@@ -7,6 +7,35 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
+func TestDeclareAnimType(t *testing.T) {
+	l := NewLuaInterpreter(nil, nil)
+	lua.BaseOpen(l.State)
+
+	l.DeclareAnimType()
+
+	assert.NoError(t, lua.DoString(l.State, `
+		walk = sequenceanim {
+			row=1, columns={0, 1, 2, 3}, duration=100,
+			events={
+				{ frame=1, event="step", sound=ref("res:sounds/step") },
+				{ frame=3, connect=true },
+			},
+		}
+	`))
+	l.Global("walk")
+	anim := l.CheckEntity(-1, ScriptEntityAnimation).(*Animation)
+	assert.Equal(t, 4, anim.Frames())
+	assert.Equal(t, []AnimationEvent{{
+		Name:  "step",
+		Sound: NewResourceRef("res", "sounds/step"),
+	}}, anim.frames[1].events)
+	assert.Equal(t, []AnimationEvent{{Connect: true}}, anim.frames[3].events)
+
+	assert.Error(t, lua.DoString(l.State, `
+		sequenceanim { row=1, columns={0}, duration=100, events={ { frame=1, event="x" } } }
+	`))
+}
+
 func TestDeclareColorType(t *testing.T) {
 	l := NewLuaInterpreter(nil, nil)
 	lua.BaseOpen(l.State)
@@ -275,3 +304,26 @@ func TestDeclareWalkBoxType(t *testing.T) {
 		}
 	`))
 }
+
+func TestDeclareTriggerType(t *testing.T) {
+	l := NewLuaInterpreter(nil, nil)
+	lua.BaseOpen(l.State)
+
+	l.DeclareTriggerType()
+
+	assert.NoError(t, lua.DoString(l.State, `
+		trap = trigger { rect = rect { x=10, y=20, w=30, h=40 } }
+		corner = trigger { vertices = { pos { x=0, y=0 }, pos { x=10, y=0 }, pos { x=0, y=10 } } }
+		function trap:enter(actor) end
+		function trap:leave(actor) end
+	`))
+	l.Global("trap")
+	trap := l.CheckEntity(-1, ScriptEntityTrigger).(*Trigger)
+	assert.True(t, trap.containsPoint(NewPosf(20, 30)))
+	assert.False(t, trap.containsPoint(NewPosf(5, 30)))
+	assert.NotNil(t, trap.FindCallback("enter"))
+	assert.NotNil(t, trap.FindCallback("leave"))
+
+	assert.Error(t, lua.DoString(l.State, `trigger { vertices = { pos { x=0, y=0 } } }`))
+	assert.Error(t, lua.DoString(l.State, `trigger {}`))
+}
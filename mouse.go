@@ -40,6 +40,11 @@ func (m *Mouse) LeftClick() bool {
 	return rl.IsMouseButtonPressed(rl.MouseLeftButton)
 }
 
+// LeftDown returns true if the left mouse button is held down.
+func (m *Mouse) LeftDown() bool {
+	return rl.IsMouseButtonDown(rl.MouseLeftButton)
+}
+
 // RightClick returns true if the right mouse button is pressed.
 func (m *Mouse) RightClick() bool {
 	return rl.IsMouseButtonPressed(rl.MouseRightButton)
@@ -9,6 +9,7 @@ import (
 // by the room scripts.
 type Object struct {
 	Class   ObjectClass             // The classes the object belongs to as OR-ed bit flags
+	Exit    *ObjectExit             // The room the object leads to, or nil if it is not an exit
 	Hotspot Rectangle               // The hotspot of the object (for mouse interaction)
 	Name    string                  // The name of the object as seen by the player
 	Owner   *Actor                  // The actor that owns the object, or nil if not picked up
@@ -21,6 +22,7 @@ type Object struct {
 	UsePos  Position                // The position the actor was when using the object
 
 	callbacks []*ScriptCallback // The callbacks declared in the object
+	player    AnimationPlayer   // The player of the animation of the current state
 	sprites   *SpriteSheet      // The sprites of the object
 }
 
@@ -92,7 +94,11 @@ func (o *Object) Draw(f *Frame) {
 	}
 	if st := o.CurrentState(); st != nil && st.Anim != nil {
 		pos := o.Pos.Sub(NewPos(o.sprites.frameSize.W/2, o.sprites.frameSize.H))
-		st.Anim.Draw(o.sprites, pos)
+		if o.Room != nil {
+			o.player.Tint(o.Room.Ambient)
+		}
+		o.player.Play(st.Anim)
+		o.player.Draw(o.sprites, pos, 1)
 	}
 }
 
@@ -126,6 +132,60 @@ func (o *Object) ItemUsePosition() (Position, Direction) {
 	return o.UsePos, o.UseDir
 }
 
+// Entrance returns the object of the other room actors enter through when walking out of this
+// exit, or nil if the object is not an exit.
+func (o *Object) Entrance() (*Object, error) {
+	if o.Exit == nil {
+		return nil, nil
+	}
+	if o.Exit.Room == nil {
+		return nil, fmt.Errorf("exit '%s' leads to no room", o.Name)
+	}
+	entrance, ok := o.Exit.Room.objects[o.Exit.Entrance]
+	if !ok {
+		return nil, fmt.Errorf("entrance '%s' of exit '%s' not found", o.Exit.Entrance, o.Name)
+	}
+	return entrance, nil
+}
+
+// IsPassable returns true if the object is an exit actors can walk through, that is, it is not
+// closed.
+func (o *Object) IsPassable() bool {
+	return o.Exit != nil && !o.Class.Is(ObjectClassOpenable)
+}
+
+// pairedDoor returns the object at the other side of the exit if it leads back to this object,
+// as the two sides of a door do, or nil otherwise.
+func (o *Object) pairedDoor() *Object {
+	other, err := o.Entrance()
+	if err != nil || other == nil || other == o {
+		return nil
+	}
+	if back, _ := other.Entrance(); back != o {
+		return nil
+	}
+	return other
+}
+
+// stateName returns the name of the given state of the object, or an empty string if the state
+// does not belong to it.
+func (o *Object) stateName(st *ObjectState) string {
+	for name, s := range o.States {
+		if s == st {
+			return name
+		}
+	}
+	return ""
+}
+
+// ObjectExit describes an object that leads to another room, like a door or a path. Actors walking
+// to it leave the room and enter the other one through the entrance object.
+type ObjectExit struct {
+	Room       *Room      // The room the exit leads to.
+	Entrance   string     // The tag of the object of the room the actors enter through.
+	Transition Transition // The transition shown when changing the room.
+}
+
 // ObjectState represents a state of an object.
 type ObjectState struct {
 	Anim   *Animation // The animation while in this state.
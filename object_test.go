@@ -0,0 +1,110 @@
+package pctk
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObject_Entrance(t *testing.T) {
+	street, bar := NewRoom(), NewRoom()
+	outside, inside := NewObject(), NewObject()
+	street.DeclareObject("door", outside)
+	bar.DeclareObject("door", inside)
+	outside.Exit = &ObjectExit{Room: bar, Entrance: "door"}
+
+	entrance, err := outside.Entrance()
+	require.NoError(t, err)
+	assert.Equal(t, inside, entrance)
+
+	entrance, err = inside.Entrance()
+	require.NoError(t, err)
+	assert.Nil(t, entrance)
+
+	inside.Exit = &ObjectExit{Room: street, Entrance: "window"}
+	_, err = inside.Entrance()
+	assert.Error(t, err)
+}
+
+func TestObject_IsPassable(t *testing.T) {
+	door := NewObject()
+	assert.False(t, door.IsPassable())
+
+	door.Exit = &ObjectExit{Room: NewRoom(), Entrance: "door"}
+	assert.True(t, door.IsPassable())
+
+	door.EnableClass(ObjectClassOpenable)
+	assert.False(t, door.IsPassable())
+}
+
+func TestObject_pairedDoor(t *testing.T) {
+	street, bar, kitchen := NewRoom(), NewRoom(), NewRoom()
+	outside, inside, back := NewObject(), NewObject(), NewObject()
+	street.DeclareObject("door", outside)
+	bar.DeclareObject("door", inside)
+	kitchen.DeclareObject("door", back)
+
+	// A one-way exit is not paired.
+	outside.Exit = &ObjectExit{Room: bar, Entrance: "door"}
+	assert.Nil(t, outside.pairedDoor())
+
+	// An exit leading back to a different object is not paired either.
+	inside.Exit = &ObjectExit{Room: kitchen, Entrance: "door"}
+	assert.Nil(t, outside.pairedDoor())
+
+	inside.Exit = &ObjectExit{Room: street, Entrance: "door"}
+	assert.Equal(t, inside, outside.pairedDoor())
+	assert.Equal(t, outside, inside.pairedDoor())
+}
+
+// pairedDoors returns the two sides of a closed door between a street and a bar.
+func pairedDoors() (outside, inside *Object) {
+	street, bar := NewRoom(), NewRoom()
+	outside, inside = NewObject(), NewObject()
+	for _, door := range []*Object{outside, inside} {
+		door.States["open"] = &ObjectState{Object: door}
+		door.States["closed"] = &ObjectState{Object: door}
+		door.State = door.States["closed"]
+		door.Class = ObjectClassOpenable
+	}
+	street.DeclareObject("door", outside)
+	bar.DeclareObject("door", inside)
+	outside.Exit = &ObjectExit{Room: bar, Entrance: "door"}
+	inside.Exit = &ObjectExit{Room: street, Entrance: "door"}
+	return outside, inside
+}
+
+func TestObjectSetState_PairedDoor(t *testing.T) {
+	outside, inside := pairedDoors()
+
+	_, err := ObjectSetState(outside.States["open"])(nil)
+	require.NoError(t, err)
+	_, err = ObjectDisableClass(outside, ObjectClassOpenable)(nil)
+	require.NoError(t, err)
+	_, err = ObjectEnableClass(outside, ObjectClassCloseable|ObjectClassPickable)(nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, inside.States["open"], inside.State)
+	assert.Equal(t, ObjectClassCloseable, inside.Class)
+	assert.True(t, inside.IsPassable())
+}
+
+func TestObjectSetState_PairedDoorNotMirrored(t *testing.T) {
+	outside, inside := pairedDoors()
+	outside.States["broken"] = &ObjectState{Object: outside}
+
+	// The other side keeps its state if it has none with the same name.
+	_, err := ObjectSetState(outside.States["broken"])(nil)
+	require.NoError(t, err)
+	assert.Equal(t, inside.States["closed"], inside.State)
+
+	// Classes other than openable and closeable belong to each side.
+	_, err = ObjectEnableClass(outside, ObjectClassPickable)(nil)
+	require.NoError(t, err)
+	assert.Equal(t, ObjectClassOpenable, inside.Class)
+	inside.EnableClass(ObjectClassPickable)
+	_, err = ObjectDisableClass(outside, ObjectClassPickable)(nil)
+	require.NoError(t, err)
+	assert.Equal(t, ObjectClassOpenable|ObjectClassPickable, inside.Class)
+}
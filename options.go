@@ -13,6 +13,27 @@ func WithScreenZoom(zoom int32) AppOption {
 	return func(a *App) { a.screenZoom = zoom }
 }
 
+// WithScreenIcon sets the image resource used as icon of the application window.
+func WithScreenIcon(ref ResourceRef) AppOption {
+	return func(a *App) { a.screenIcon = ref }
+}
+
+// WithLanguage sets the language of the game. Scripts can read it from the LANGUAGE global to
+// choose the texts to show.
+func WithLanguage(lang string) AppOption {
+	return func(a *App) { a.language = lang }
+}
+
+// WithStartRoom makes the first room shown by the game be the one exported with the given name by
+// the given script, regardless of the room requested by the scripts. This is useful to test a room
+// without playing the game up to it.
+func WithStartRoom(script ResourceRef, name string) AppOption {
+	return func(a *App) {
+		a.startRoom.script = script
+		a.startRoom.name = name
+	}
+}
+
 // WithDebugMode allows you to enable the debug mode.
 func WithDebugMode() AppOption {
 	return func(a *App) {
@@ -23,4 +44,5 @@ func WithDebugMode() AppOption {
 var defaultAppOptions = []AppOption{
 	WithScreenCaption("Point&Click Toolkit"),
 	WithScreenZoom(4),
+	WithLanguage("en"),
 }
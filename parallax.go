@@ -0,0 +1,95 @@
+package pctk
+
+import (
+	"log"
+	"math"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// ParallaxLayer is an image of a room that moves at its own pace when the camera scrolls, giving
+// depth to the scene. Layers are drawn behind the room background, showing through its transparent
+// areas, unless they are in front of the room plane, in which case they are drawn over its actors,
+// objects and foreground layers.
+type ParallaxLayer struct {
+	Image  ResourceRef // The reference to the layer image.
+	Pos    Position    // The position of the layer image when the camera is at the top-left corner of the room.
+	Factor float32     // The speed of the layer relative to the camera: 0 is fixed to the screen, 1 moves with the room.
+	Scroll float32     // The speed in pixels per second the layer scrolls by itself, repeating horizontally.
+	Front  bool        // Whether the layer is drawn in front of the room plane.
+
+	image  *Image
+	offset float32
+}
+
+// Load the layer resources.
+func (l *ParallaxLayer) Load(res ResourceLoader) {
+	if l.image == nil {
+		l.image = res.LoadImage(l.Image)
+		if l.image == nil {
+			log.Fatalf("Parallax layer image not found: %s", l.Image)
+		}
+	}
+}
+
+// Draw renders the layer for a camera at the given position of the room, showing an area of the
+// given width, tinted with the given color.
+func (l *ParallaxLayer) Draw(cam Position, width int, tint Color) {
+	w := int(l.image.Width())
+	l.scroll(rl.GetFrameTime(), w)
+	for _, pos := range l.positions(cam, width, w) {
+		l.image.Draw(pos, tint)
+	}
+}
+
+// scroll advances the layer by itself for the given seconds, wrapping the offset around the given
+// image width.
+func (l *ParallaxLayer) scroll(dt float32, w int) {
+	if l.Scroll == 0 || w <= 0 {
+		return
+	}
+	l.offset = float32(math.Mod(float64(l.offset+l.Scroll*dt), float64(w)))
+}
+
+// positions returns where the layer image of the given width is drawn for a camera at the given
+// position of the room, showing an area of the given width. Scrolling layers repeat horizontally
+// to cover the whole area.
+func (l *ParallaxLayer) positions(cam Position, width, w int) []Position {
+	x := float32(l.Pos.X) + float32(cam.X)*(1-l.Factor)
+	y := int(float32(l.Pos.Y) + float32(cam.Y)*(1-l.Factor))
+	if l.Scroll == 0 || w <= 0 {
+		return []Position{NewPos(int(x), y)}
+	}
+
+	x += l.offset
+	for x > float32(cam.X) {
+		x -= float32(w)
+	}
+	var positions []Position
+	for ; x < float32(cam.X+width); x += float32(w) {
+		positions = append(positions, NewPos(int(x), y))
+	}
+	return positions
+}
+
+// DeclareParallaxLayer declares a parallax layer in the room. Layers are drawn in the order they
+// are declared.
+func (r *Room) DeclareParallaxLayer(l *ParallaxLayer) {
+	r.parallax = append(r.parallax, l)
+}
+
+// drawParallax renders the parallax layers of the room that are in front of the room plane or
+// behind it, as requested.
+func (r *Room) drawParallax(frame *Frame, front bool) {
+	var cam Position
+	width := ScreenWidth
+	if frame.Camera != nil {
+		cam = frame.Camera.Target()
+		width = int(float32(rl.GetScreenWidth()) / frame.Camera.Zoom())
+	}
+	for _, l := range r.parallax {
+		if l.Front == front {
+			l.Draw(cam, width, r.Ambient)
+		}
+	}
+}
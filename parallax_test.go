@@ -0,0 +1,28 @@
+package pctk
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParallaxLayer_positions(t *testing.T) {
+	mountains := &ParallaxLayer{Pos: NewPos(0, 10), Factor: 0.5}
+	assert.Equal(t, []Position{{X: 100, Y: 30}}, mountains.positions(NewPos(200, 40), 320, 400))
+
+	clouds := &ParallaxLayer{Factor: 1, Scroll: 20}
+	assert.Equal(t, []Position{{X: 0}, {X: 150}, {X: 300}}, clouds.positions(NewPos(0, 0), 320, 150))
+
+	clouds.scroll(2.5, 150)
+	assert.Equal(t, float32(50), clouds.offset)
+	assert.Equal(t, []Position{{X: -100}, {X: 50}, {X: 200}}, clouds.positions(NewPos(0, 0), 320, 150))
+
+	// The offset wraps around the image width, so it never grows unbounded.
+	clouds.scroll(6, 150)
+	assert.Equal(t, float32(20), clouds.offset)
+
+	clouds.Scroll = -20
+	clouds.scroll(2, 150)
+	assert.Equal(t, float32(-20), clouds.offset)
+	assert.Equal(t, []Position{{X: -20}, {X: 130}, {X: 280}}, clouds.positions(NewPos(100, 0), 320, 150))
+}
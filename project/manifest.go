@@ -0,0 +1,128 @@
+package project
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/apoloval/pctk"
+	"gopkg.in/yaml.v3"
+)
+
+// ManifestFile is the name of the file that describes a game project.
+const ManifestFile = "game.yml"
+
+// Manifest is the description of a game project.
+type Manifest struct {
+	Title      string   // The title of the game, shown as window caption
+	Resolution string   // The resolution of the game screen as WxH
+	Zoom       int32    // The zoom factor of the game screen
+	Packages   []string // The resource packages of the game
+	Boot       string   // The reference to the boot script
+	Language   string   // The default language of the game
+	Icon       string   // The reference to the image used as window icon
+	Debug      bool     // Whether the debug mode is enabled
+
+	dir string
+}
+
+// LoadManifest loads the manifest of the game project in the given directory.
+func LoadManifest(dir string) (*Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	if err != nil {
+		return nil, err
+	}
+
+	man := &Manifest{dir: dir}
+	if err := yaml.Unmarshal(data, man); err != nil {
+		return nil, fmt.Errorf("error decoding %s: %w", ManifestFile, err)
+	}
+	return man, nil
+}
+
+// Dir returns the directory of the game project.
+func (m *Manifest) Dir() string {
+	return m.dir
+}
+
+// Validate checks the manifest is consistent and its resource packages are present.
+func (m *Manifest) Validate() error {
+	if m.Boot == "" {
+		return fmt.Errorf("missing boot script in %s", ManifestFile)
+	}
+	if _, err := pctk.ParseResourceRef(m.Boot); err != nil {
+		return fmt.Errorf("invalid boot script: %w", err)
+	}
+	if m.Icon != "" {
+		if _, err := pctk.ParseResourceRef(m.Icon); err != nil {
+			return fmt.Errorf("invalid icon: %w", err)
+		}
+	}
+	if m.Resolution != "" {
+		expected := fmt.Sprintf("%dx%d", pctk.ScreenWidth, pctk.ScreenHeight)
+		if m.Resolution != expected {
+			return fmt.Errorf("unsupported resolution %s (only %s is supported)",
+				m.Resolution, expected)
+		}
+	}
+	if !slices.Contains(m.Packages, m.BootScript().Package().String()) {
+		return fmt.Errorf("boot script package %s is not listed in packages", m.BootScript().Package())
+	}
+	return nil
+}
+
+// Loader returns a loader of the resource packages of the game. The packages are read up front,
+// so a missing or outdated package is reported before the game starts.
+func (m *Manifest) Loader() (*pctk.ResourceFileLoader, error) {
+	loader := pctk.NewResourceFileLoader(m.dir)
+	for _, pkg := range m.Packages {
+		if err := loader.Preload(pctk.ResourcePackage(pkg)); err != nil {
+			return nil, fmt.Errorf("resource package %s not available: %w", pkg, err)
+		}
+	}
+	return loader, nil
+}
+
+// NewApp validates the manifest and creates the application it describes, with the given options
+// on top of the ones of the manifest. The boot script is run as soon as the application runs.
+func (m *Manifest) NewApp(opts ...pctk.AppOption) (*pctk.App, error) {
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	loader, err := m.Loader()
+	if err != nil {
+		return nil, err
+	}
+	app := pctk.New(loader, append(m.AppOptions(), opts...)...)
+	app.RunCommand(pctk.ScriptRun{ScriptRef: m.BootScript()})
+	return app, nil
+}
+
+// BootScript returns the reference to the boot script.
+func (m *Manifest) BootScript() pctk.ResourceRef {
+	ref, _ := pctk.ParseResourceRef(m.Boot)
+	return ref
+}
+
+// AppOptions returns the application options described by the manifest.
+func (m *Manifest) AppOptions() []pctk.AppOption {
+	var opts []pctk.AppOption
+	if m.Title != "" {
+		opts = append(opts, pctk.WithScreenCaption(m.Title))
+	}
+	if m.Zoom > 0 {
+		opts = append(opts, pctk.WithScreenZoom(m.Zoom))
+	}
+	if m.Language != "" {
+		opts = append(opts, pctk.WithLanguage(m.Language))
+	}
+	if m.Icon != "" {
+		ref, _ := pctk.ParseResourceRef(m.Icon)
+		opts = append(opts, pctk.WithScreenIcon(ref))
+	}
+	if m.Debug {
+		opts = append(opts, pctk.WithDebugMode())
+	}
+	return opts
+}
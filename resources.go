@@ -81,6 +81,25 @@ func (r ResourceRef) BinaryEncode(w io.Writer) (int, error) {
 	return BinaryEncode(w, r.String())
 }
 
+// BinaryDecode decodes the resource reference from a binary format. An empty string is decoded as
+// a null reference.
+func (r *ResourceRef) BinaryDecode(rd io.Reader) error {
+	var s string
+	if err := BinaryDecode(rd, &s); err != nil {
+		return err
+	}
+	if s == "" {
+		*r = ResourceRefNull
+		return nil
+	}
+	ref, err := ParseResourceRef(s)
+	if err != nil {
+		return err
+	}
+	*r = ref
+	return nil
+}
+
 // IsNull returns true if the reference is null.
 func (r ResourceRef) IsNull() bool {
 	return len(r.pkg) == 0 && len(r.id) == 0
@@ -109,6 +128,14 @@ type ResourceLoader interface {
 	// found.
 	LoadMusic(ref ResourceRef) *MusicTrack
 
+	// LoadRoom loads a room description from the given ref. It returns nil if the room is not
+	// found.
+	LoadRoom(ref ResourceRef) *RoomDescription
+
+	// LoadRoomGeometry loads a room geometry from the given ref. It returns nil if the geometry
+	// is not found.
+	LoadRoomGeometry(ref ResourceRef) *RoomGeometry
+
 	// LoadScript loads a script from the given ref. It returns nil if the script is not found.
 	LoadScript(ref ResourceRef) *Script
 
@@ -124,23 +151,27 @@ type ResourceLoader interface {
 // ResourceBundle is a bundle of resources that are loaded in memory. This can be used for
 // testing purposes mainly.
 type ResourceBundle struct {
-	costumes map[ResourceRef]*Costume
-	images   map[ResourceRef]*Image
-	music    map[ResourceRef]*MusicTrack
-	scripts  map[ResourceRef]*Script
-	sounds   map[ResourceRef]*SoundTrack
-	sprites  map[ResourceRef]*SpriteSheet
+	costumes   map[ResourceRef]*Costume
+	geometries map[ResourceRef]*RoomGeometry
+	images     map[ResourceRef]*Image
+	music      map[ResourceRef]*MusicTrack
+	rooms      map[ResourceRef]*RoomDescription
+	scripts    map[ResourceRef]*Script
+	sounds     map[ResourceRef]*SoundTrack
+	sprites    map[ResourceRef]*SpriteSheet
 }
 
 // NewResourceBundle creates a new resource bundle that can be used as resource loader.
 func NewResourceBundle() *ResourceBundle {
 	return &ResourceBundle{
-		costumes: make(map[ResourceRef]*Costume),
-		images:   make(map[ResourceRef]*Image),
-		music:    make(map[ResourceRef]*MusicTrack),
-		scripts:  make(map[ResourceRef]*Script),
-		sounds:   make(map[ResourceRef]*SoundTrack),
-		sprites:  make(map[ResourceRef]*SpriteSheet),
+		costumes:   make(map[ResourceRef]*Costume),
+		geometries: make(map[ResourceRef]*RoomGeometry),
+		images:     make(map[ResourceRef]*Image),
+		music:      make(map[ResourceRef]*MusicTrack),
+		rooms:      make(map[ResourceRef]*RoomDescription),
+		scripts:    make(map[ResourceRef]*Script),
+		sounds:     make(map[ResourceRef]*SoundTrack),
+		sprites:    make(map[ResourceRef]*SpriteSheet),
 	}
 }
 
@@ -159,6 +190,16 @@ func (c *ResourceBundle) PutMusic(ref ResourceRef, m *MusicTrack) {
 	c.music[ref] = m
 }
 
+// PutRoom adds a room description to the bundle.
+func (c *ResourceBundle) PutRoom(ref ResourceRef, d *RoomDescription) {
+	c.rooms[ref] = d
+}
+
+// PutRoomGeometry adds a room geometry to the bundle.
+func (c *ResourceBundle) PutRoomGeometry(ref ResourceRef, g *RoomGeometry) {
+	c.geometries[ref] = g
+}
+
 // PutScript adds a script to the bundle.
 func (c *ResourceBundle) PutScript(ref ResourceRef, s *Script) {
 	c.scripts[ref] = s
@@ -189,6 +230,17 @@ func (c *ResourceBundle) LoadMusic(ref ResourceRef) *MusicTrack {
 	return c.music[ref]
 }
 
+// LoadRoom loads a room description from the given ref. It returns nil if the room is not found.
+func (c *ResourceBundle) LoadRoom(ref ResourceRef) *RoomDescription {
+	return c.rooms[ref]
+}
+
+// LoadRoomGeometry loads a room geometry from the given ref. It returns nil if the geometry is
+// not found.
+func (c *ResourceBundle) LoadRoomGeometry(ref ResourceRef) *RoomGeometry {
+	return c.geometries[ref]
+}
+
 // LoadScript loads a script from the given ref. It returns nil if the script is not found.
 func (c *ResourceBundle) LoadScript(ref ResourceRef) *Script {
 	return c.scripts[ref]
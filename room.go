@@ -4,27 +4,50 @@ import (
 	"errors"
 	"fmt"
 	"log"
-	"slices"
+	"time"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
 )
 
-// RoomCameraSpeed is the speed at which the camera moves in the room.
+// RoomCameraSpeed is the speed in pixels per frame at which the camera scrolls in the room when it
+// is not panning.
 const RoomCameraSpeed = 2
 
 // Room represents a room in the game.
 type Room struct {
 	Background ResourceRef // The reference to the background image
+	Zoom       float32     // The zoom of the camera in the room
+	Ambient    Color       // The color the room and everything in it is tinted with
+	Darkness   float32     // How dark the room is out of its lights, from 0 (lit) to 1 (pitch black)
 
-	actors     []*Actor           // The actors in the room
-	background *Image             // The background image of the room
-	callbacks  []*ScriptCallback  // The callbacks declared in the room
-	objects    map[string]*Object // The objects declared in the room
-	wbmatrix   *WalkBoxMatrix     // The wbmatrix defines the walkable areas within the room and their adjacency.
+	actors     []*Actor                 // The actors in the room
+	background *Image                   // The background image of the room
+	callbacks  []*ScriptCallback        // The callbacks declared in the room
+	emitters   map[string]*SoundEmitter // The sound emitters of the room
+	entrances  map[string]Position      // The named positions where actors enter the room
+	exiting    bool                     // Whether the room is being exited, so it is not updated anymore
+	layers     []*RoomLayer             // The foreground layers of the room
+	lightmap   rl.RenderTexture2D       // The darkness of the room with its lights carved out
+	lights     []*RoomLight             // The lights carried by actors in the room
+	objects    map[string]*Object       // The objects declared in the room
+	parallax   []*ParallaxLayer         // The parallax layers of the room
+	placed     map[string]*Actor        // The actors placed in the room by its description
+	timers     []*RoomTimer             // The timers running while the room is shown
+	triggers   []*Trigger               // The triggers declared in the room
+	updated    time.Time                // When the update function of the room was last called
+	updating   Future                   // The call to the update function in progress, if any
+	wbmatrix   *WalkBoxMatrix           // The wbmatrix defines the walkable areas within the room and their adjacency.
 }
 
 // NewRoom creates a new room ready to be used.
 func NewRoom() *Room {
 	return &Room{
-		objects: make(map[string]*Object),
+		Zoom:      1,
+		Ambient:   White,
+		emitters:  make(map[string]*SoundEmitter),
+		entrances: make(map[string]Position),
+		objects:   make(map[string]*Object),
+		placed:    make(map[string]*Actor),
 	}
 }
 
@@ -56,6 +79,18 @@ func (r *Room) DeclareObject(tag string, obj *Object) {
 	r.objects[tag] = obj
 }
 
+// DeclareTrigger declares a trigger in the room.
+func (r *Room) DeclareTrigger(tag string, t *Trigger) {
+	for _, other := range r.triggers {
+		if other.triggerID == tag {
+			log.Fatalf("Trigger already declared: %s", tag)
+		}
+	}
+	t.triggerID = tag
+	t.room = r
+	r.triggers = append(r.triggers, t)
+}
+
 // DeclareWalkBoxMatrix declares walk box matrix for the room.
 func (r *Room) DeclareWalkBoxMatrix(walkboxes []*WalkBox) {
 	r.wbmatrix = NewWalkBoxMatrix(walkboxes)
@@ -63,7 +98,8 @@ func (r *Room) DeclareWalkBoxMatrix(walkboxes []*WalkBox) {
 
 // Draw renders the room in the viewport.
 func (r *Room) Draw(frame *Frame) {
-	r.background.Draw(NewPos(0, 0), White)
+	r.drawParallax(frame, false)
+	r.background.Draw(NewPos(0, 0), r.Ambient)
 	items := make([]RoomItem, 0, len(r.actors)+len(r.objects))
 	for _, actor := range r.actors {
 		items = append(items, actor)
@@ -71,16 +107,18 @@ func (r *Room) Draw(frame *Frame) {
 	for _, obj := range r.objects {
 		items = append(items, obj)
 	}
-	slices.SortFunc(items, func(a, b RoomItem) int {
-		return a.ItemPosition().Y - b.ItemPosition().Y
-	})
-	for _, item := range items {
-		item.Draw(frame)
-	}
+	r.drawLayers(frame, items)
+	r.drawParallax(frame, true)
+	r.drawLightMap()
 
 	if frame.DebugEnabled && r.wbmatrix != nil {
 		r.wbmatrix.Draw()
 	}
+	if frame.DebugEnabled {
+		for _, t := range r.triggers {
+			t.draw()
+		}
+	}
 }
 
 // FindCallback returns the callback with the given name, or nil if not found.
@@ -103,14 +141,36 @@ func (r *Room) GetScriptField(name string) *ScriptEntityValue {
 			}
 		}
 	}
-	for _, wb := range r.wbmatrix.walkBoxes {
-		if wb.walkBoxID == name {
+	if r.wbmatrix != nil {
+		for _, wb := range r.wbmatrix.walkBoxes {
+			if wb.walkBoxID == name {
+				return &ScriptEntityValue{
+					Type:     ScriptEntityWalkBox,
+					UserData: wb,
+				}
+			}
+		}
+	}
+	for _, t := range r.triggers {
+		if t.triggerID == name {
 			return &ScriptEntityValue{
-				Type:     ScriptEntityWalkBox,
-				UserData: wb,
+				Type:     ScriptEntityTrigger,
+				UserData: t,
 			}
 		}
 	}
+	if actor, ok := r.placed[name]; ok {
+		return &ScriptEntityValue{
+			Type:     ScriptEntityActor,
+			UserData: actor,
+		}
+	}
+	if pos, ok := r.entrances[name]; ok {
+		return &ScriptEntityValue{
+			Type:     ScriptEntityPos,
+			UserData: pos,
+		}
+	}
 	return nil
 }
 
@@ -122,9 +182,18 @@ func (r *Room) Load(res ResourceLoader) {
 			log.Fatalf("Background image not found: %s", r.Background)
 		}
 	}
+	for _, layer := range r.parallax {
+		layer.Load(res)
+	}
+	for _, layer := range r.layers {
+		layer.Load(res)
+	}
 	for _, obj := range r.objects {
 		obj.Load(res)
 	}
+	for _, actor := range r.actors {
+		actor.Load(res)
+	}
 }
 
 // ItemAt returns the item at the given position in the room.
@@ -197,13 +266,32 @@ func (a *App) DeclareRoom(room *Room) error {
 	return nil
 }
 
-// StartRoom starts the given room in the application.
-func (a *App) StartRoom(room *Room, entrance *Object) Future {
+// StartRoom starts the given room in the application with the given transition. If a start room
+// was given as application option, it is started instead of the first room requested.
+func (a *App) StartRoom(room *Room, entrance *Object, tr Transition) Future {
+	if !a.startRoom.script.IsNull() {
+		script, name := a.startRoom.script, a.startRoom.name
+		a.startRoom.script = ResourceRefNull
+		override, err := a.exportedRoom(script, name)
+		if err != nil {
+			return AlreadyFailed(err)
+		}
+		room, entrance = override, nil
+	}
 	for _, r := range a.rooms {
 		if r == room {
 			room.Load(a.res)
-			return a.viewport.ActivateRoom(room, entrance)
+			return a.viewport.ActivateRoom(room, entrance, tr)
 		}
 	}
 	return AlreadyFailed(errors.New("Room not declared"))
 }
+
+func (a *App) exportedRoom(script ResourceRef, name string) (*Room, error) {
+	for _, exp := range a.LoadScript(script).Exports() {
+		if exp.Name == name && exp.Type == ScriptEntityRoom {
+			return exp.UserData.(*Room), nil
+		}
+	}
+	return nil, fmt.Errorf("room '%s' not exported by script %s", name, script)
+}
@@ -0,0 +1,346 @@
+package pctk
+
+import (
+	"fmt"
+	"io"
+)
+
+// RoomDescription is the declarative description of a room: its background, walkboxes, objects
+// and the actors placed in it. It is packed from room manifests and built into a ready room, to
+// which scripts attach their callbacks.
+type RoomDescription struct {
+	Background ResourceRef           // The reference to the background image.
+	Zoom       float32               // The zoom of the camera in the room, or zero for no zoom.
+	Layers     []LayerDescription    // The foreground layers of the room.
+	Parallax   []ParallaxDescription // The parallax layers of the room.
+	WalkBoxes  []WalkBoxGeometry     // The walkable areas of the room.
+	Objects    []ObjectDescription   // The objects declared in the room.
+	Actors     []ActorDescription    // The actors placed in the room.
+}
+
+// LayerDescription is the description of a foreground layer of a room.
+type LayerDescription struct {
+	Image    ResourceRef
+	Pos      Position
+	Baseline int
+}
+
+// ParallaxDescription is the description of a parallax layer of a room.
+type ParallaxDescription struct {
+	Image  ResourceRef
+	Pos    Position
+	Factor float32
+	Scroll float32
+	Front  bool
+}
+
+// ObjectDescription is the description of an object of a room.
+type ObjectDescription struct {
+	ID      string
+	Name    string
+	Class   ObjectClass
+	Hotspot Rectangle
+	Pos     Position
+	UsePos  Position
+	UseDir  Direction
+	Sprites ResourceRef
+	State   string                   // The name of the initial state, or empty if none.
+	States  []ObjectStateDescription // The states of the object.
+}
+
+// ObjectStateDescription is the description of a named state of an object.
+type ObjectStateDescription struct {
+	Name string
+	Anim *Animation // The animation while in this state, or nil if the object is not drawn.
+}
+
+// ActorDescription is the description of an actor placed in a room.
+type ActorDescription struct {
+	ID        string
+	Name      string
+	Costume   ResourceRef
+	Pos       Position
+	Dir       Direction
+	Size      Size
+	TalkColor Color
+	UsePos    Position
+	UseDir    Direction
+}
+
+// Build creates a new room from the description. The actors placed in the room are located in it,
+// but they are not declared in any application.
+func (d *RoomDescription) Build() (*Room, error) {
+	room := NewRoom()
+	room.Background = d.Background
+	if d.Zoom > 0 {
+		room.Zoom = d.Zoom
+	}
+	for _, ld := range d.Layers {
+		room.DeclareLayer(&RoomLayer{Image: ld.Image, Pos: ld.Pos, Baseline: ld.Baseline})
+	}
+	for _, pd := range d.Parallax {
+		room.DeclareParallaxLayer(&ParallaxLayer{
+			Image:  pd.Image,
+			Pos:    pd.Pos,
+			Factor: pd.Factor,
+			Scroll: pd.Scroll,
+			Front:  pd.Front,
+		})
+	}
+
+	if len(d.WalkBoxes) > 0 {
+		walkboxes := make([]*WalkBox, len(d.WalkBoxes))
+		for i, wbg := range d.WalkBoxes {
+			wb, err := wbg.build(room)
+			if err != nil {
+				return nil, err
+			}
+			walkboxes[i] = wb
+		}
+		room.DeclareWalkBoxMatrix(walkboxes)
+	}
+
+	for _, od := range d.Objects {
+		if _, ok := room.objects[od.ID]; ok {
+			return nil, fmt.Errorf("object %s declared twice", od.ID)
+		}
+		obj := NewObject()
+		obj.Name = od.Name
+		obj.Class = od.Class
+		obj.Hotspot = od.Hotspot
+		obj.Pos = od.Pos
+		obj.UsePos = od.UsePos
+		obj.UseDir = od.UseDir
+		obj.Sprites = od.Sprites
+		for _, sd := range od.States {
+			obj.States[sd.Name] = &ObjectState{Anim: sd.Anim, Object: obj}
+		}
+		if od.State != "" {
+			obj.State = obj.States[od.State]
+			if obj.State == nil {
+				return nil, fmt.Errorf("object %s has no state %s", od.ID, od.State)
+			}
+		}
+		room.DeclareObject(od.ID, obj)
+	}
+
+	for _, ad := range d.Actors {
+		if _, ok := room.placed[ad.ID]; ok {
+			return nil, fmt.Errorf("actor %s placed twice", ad.ID)
+		}
+		actor := NewActor(ad.Name)
+		actor.Costume = ad.Costume
+		actor.Size = ad.Size
+		actor.TalkColor = ad.TalkColor
+		actor.UsePos = ad.UsePos
+		actor.UseDir = ad.UseDir
+		room.PutActor(actor)
+		actor.Locate(room, ad.Pos, ad.Dir)
+		room.placed[ad.ID] = actor
+	}
+	return room, nil
+}
+
+// BinaryEncode encodes the room description to a binary format. The encoded format is:
+// - string: the background reference.
+// - float32: the camera zoom.
+// - uint16: the number of layers, followed by each one as in LayerDescription.BinaryEncode.
+// - uint16: the number of parallax layers, followed by each one as in
+// ParallaxDescription.BinaryEncode.
+// - uint16: the number of walkboxes, followed by each one as in WalkBoxGeometry.BinaryEncode.
+// - uint16: the number of objects.
+// - for each object:
+//   - string: the ID and the name.
+//   - uint64: the class.
+//   - int16: the X, Y, width and height of the hotspot.
+//   - int16: the X and Y of the position and of the use position.
+//   - byte: the use direction.
+//   - string: the sprites reference and the initial state.
+//   - uint16: the number of states.
+//   - for each state, the string name and whether it has an animation, followed by it if so.
+//
+// - uint16: the number of actors.
+// - for each actor:
+//   - string: the ID, the name and the costume reference.
+//   - int16: the X and Y of the position, followed by the direction byte.
+//   - int16: the width and height of the actor.
+//   - byte: the red, green, blue and alpha components of the talk color.
+//   - int16: the X and Y of the use position, followed by the use direction byte.
+func (d *RoomDescription) BinaryEncode(w io.Writer) (n int, err error) {
+	add := func(o ...any) {
+		if err != nil {
+			return
+		}
+		var nn int
+		nn, err = BinaryEncode(w, o...)
+		n += nn
+	}
+
+	add(d.Background, d.Zoom)
+	add(uint16(len(d.Layers)))
+	for _, l := range d.Layers {
+		add(l)
+	}
+
+	add(uint16(len(d.Parallax)))
+	for _, p := range d.Parallax {
+		add(p)
+	}
+
+	add(uint16(len(d.WalkBoxes)))
+	for _, wb := range d.WalkBoxes {
+		add(wb)
+	}
+
+	add(uint16(len(d.Objects)))
+	for _, o := range d.Objects {
+		add(o.ID, o.Name, uint64(o.Class))
+		add(int16(o.Hotspot.Pos.X), int16(o.Hotspot.Pos.Y), int16(o.Hotspot.Size.W), int16(o.Hotspot.Size.H))
+		add(int16(o.Pos.X), int16(o.Pos.Y), int16(o.UsePos.X), int16(o.UsePos.Y), byte(o.UseDir))
+		add(o.Sprites, o.State, uint16(len(o.States)))
+		for _, s := range o.States {
+			add(s.Name, s.Anim != nil)
+			if s.Anim != nil {
+				add(s.Anim)
+			}
+		}
+	}
+
+	add(uint16(len(d.Actors)))
+	for _, a := range d.Actors {
+		add(a.ID, a.Name, a.Costume)
+		add(int16(a.Pos.X), int16(a.Pos.Y), byte(a.Dir))
+		add(int16(a.Size.W), int16(a.Size.H))
+		add(a.TalkColor.R, a.TalkColor.G, a.TalkColor.B, a.TalkColor.A)
+		add(int16(a.UsePos.X), int16(a.UsePos.Y), byte(a.UseDir))
+	}
+	return n, err
+}
+
+// BinaryDecode decodes the room description from a binary format. See
+// RoomDescription.BinaryEncode for the format.
+func (d *RoomDescription) BinaryDecode(r io.Reader) error {
+	var count uint16
+	if err := BinaryDecode(r, &d.Background, &d.Zoom, &count); err != nil {
+		return err
+	}
+	d.Layers = make([]LayerDescription, count)
+	for i := range d.Layers {
+		if err := BinaryDecode(r, &d.Layers[i]); err != nil {
+			return err
+		}
+	}
+
+	if err := BinaryDecode(r, &count); err != nil {
+		return err
+	}
+	d.Parallax = make([]ParallaxDescription, count)
+	for i := range d.Parallax {
+		if err := BinaryDecode(r, &d.Parallax[i]); err != nil {
+			return err
+		}
+	}
+
+	if err := BinaryDecode(r, &count); err != nil {
+		return err
+	}
+	d.WalkBoxes = make([]WalkBoxGeometry, count)
+	for i := range d.WalkBoxes {
+		if err := BinaryDecode(r, &d.WalkBoxes[i]); err != nil {
+			return err
+		}
+	}
+
+	if err := BinaryDecode(r, &count); err != nil {
+		return err
+	}
+	d.Objects = make([]ObjectDescription, count)
+	for i := range d.Objects {
+		o := &d.Objects[i]
+		var class uint64
+		var hx, hy, hw, hh, x, y, ux, uy int16
+		var dir byte
+		var states uint16
+		err := BinaryDecode(r, &o.ID, &o.Name, &class, &hx, &hy, &hw, &hh, &x, &y, &ux, &uy, &dir,
+			&o.Sprites, &o.State, &states)
+		if err != nil {
+			return err
+		}
+		o.Class = ObjectClass(class)
+		o.Hotspot = NewRect(int(hx), int(hy), int(hw), int(hh))
+		o.Pos = NewPos(int(x), int(y))
+		o.UsePos = NewPos(int(ux), int(uy))
+		o.UseDir = Direction(dir)
+		o.States = make([]ObjectStateDescription, states)
+		for j := range o.States {
+			s := &o.States[j]
+			var hasAnim bool
+			if err := BinaryDecode(r, &s.Name, &hasAnim); err != nil {
+				return err
+			}
+			if hasAnim {
+				s.Anim = new(Animation)
+				if err := BinaryDecode(r, s.Anim); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	if err := BinaryDecode(r, &count); err != nil {
+		return err
+	}
+	d.Actors = make([]ActorDescription, count)
+	for i := range d.Actors {
+		a := &d.Actors[i]
+		var x, y, w, h, ux, uy int16
+		var dir, useDir byte
+		err := BinaryDecode(r, &a.ID, &a.Name, &a.Costume, &x, &y, &dir, &w, &h,
+			&a.TalkColor.R, &a.TalkColor.G, &a.TalkColor.B, &a.TalkColor.A, &ux, &uy, &useDir)
+		if err != nil {
+			return err
+		}
+		a.Pos = NewPos(int(x), int(y))
+		a.Dir = Direction(dir)
+		a.Size = NewSize(int(w), int(h))
+		a.UsePos = NewPos(int(ux), int(uy))
+		a.UseDir = Direction(useDir)
+	}
+	return nil
+}
+
+// BinaryEncode encodes the layer description to a binary format. The encoded format is the string
+// image reference, followed by the int16 X and Y of its position and the int16 baseline.
+func (l LayerDescription) BinaryEncode(w io.Writer) (int, error) {
+	return BinaryEncode(w, l.Image, int16(l.Pos.X), int16(l.Pos.Y), int16(l.Baseline))
+}
+
+// BinaryDecode decodes the layer description from a binary format. See
+// LayerDescription.BinaryEncode for the format.
+func (l *LayerDescription) BinaryDecode(r io.Reader) error {
+	var x, y, baseline int16
+	if err := BinaryDecode(r, &l.Image, &x, &y, &baseline); err != nil {
+		return err
+	}
+	l.Pos = NewPos(int(x), int(y))
+	l.Baseline = int(baseline)
+	return nil
+}
+
+// BinaryEncode encodes the parallax layer description to a binary format. The encoded format is
+// the string image reference, followed by the int16 X and Y of its position, the float32 factor and
+// scroll speed, and whether it is in front of the room plane.
+func (p ParallaxDescription) BinaryEncode(w io.Writer) (int, error) {
+	return BinaryEncode(w, p.Image, int16(p.Pos.X), int16(p.Pos.Y), p.Factor, p.Scroll, p.Front)
+}
+
+// BinaryDecode decodes the parallax layer description from a binary format. See
+// ParallaxDescription.BinaryEncode for the format.
+func (p *ParallaxDescription) BinaryDecode(r io.Reader) error {
+	var x, y int16
+	if err := BinaryDecode(r, &p.Image, &x, &y, &p.Factor, &p.Scroll, &p.Front); err != nil {
+		return err
+	}
+	p.Pos = NewPos(int(x), int(y))
+	return nil
+}
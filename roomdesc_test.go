@@ -0,0 +1,114 @@
+package pctk_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/apoloval/pctk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testRoomDescription() *pctk.RoomDescription {
+	return &pctk.RoomDescription{
+		Background: pctk.NewResourceRef("resources", "backgrounds/hall"),
+		Zoom:       1.5,
+		Layers: []pctk.LayerDescription{
+			{Image: pctk.NewResourceRef("resources", "backgrounds/hall-counter"), Pos: pctk.NewPos(0, 80), Baseline: 120},
+		},
+		Parallax: []pctk.ParallaxDescription{
+			{Image: pctk.NewResourceRef("resources", "backgrounds/sky"), Factor: 0.25, Scroll: 4},
+			{Image: pctk.NewResourceRef("resources", "backgrounds/bushes"), Pos: pctk.NewPos(0, 160), Factor: 1.5, Front: true},
+		},
+		WalkBoxes: []pctk.WalkBoxGeometry{
+			{
+				ID:       "floor",
+				Vertices: []pctk.Position{{X: 0, Y: 100}, {X: 320, Y: 100}, {X: 320, Y: 140}, {X: 0, Y: 140}},
+				Scale:    1,
+				Mask:     1,
+				Enabled:  true,
+			},
+		},
+		Objects: []pctk.ObjectDescription{
+			{
+				ID:      "door",
+				Name:    "door",
+				Class:   pctk.ObjectClassOpenable.Enable(pctk.ObjectClassCloseable),
+				Hotspot: pctk.NewRect(136, 40, 48, 60),
+				Pos:     pctk.NewPos(136, 100),
+				UsePos:  pctk.NewPos(160, 104),
+				UseDir:  pctk.DirUp,
+				Sprites: pctk.NewResourceRef("resources", "sprites/objects"),
+				State:   "closed",
+				States: []pctk.ObjectStateDescription{
+					{Name: "closed", Anim: pctk.NewAnimation().AddFrames(100*time.Millisecond, 0, 0)},
+					{Name: "open", Anim: pctk.NewAnimation().AddFrames(100*time.Millisecond, 0, 1, 2).
+						AddEvent(1, pctk.AnimationEvent{
+							Name:  "creak",
+							Sound: pctk.NewResourceRef("resources", "sounds/creak"),
+						})},
+					{Name: "gone"},
+				},
+			},
+		},
+		Actors: []pctk.ActorDescription{
+			{
+				ID:        "guard",
+				Name:      "Guard",
+				Costume:   pctk.NewResourceRef("resources", "costumes/guard"),
+				Pos:       pctk.NewPos(200, 120),
+				Dir:       pctk.DirLeft,
+				Size:      pctk.NewSize(32, 48),
+				TalkColor: pctk.Color{R: 255, G: 0, B: 0, A: 255},
+				UsePos:    pctk.NewPos(170, 120),
+				UseDir:    pctk.DirRight,
+			},
+		},
+	}
+}
+
+func TestRoomDescription_BinaryEncodeDecode(t *testing.T) {
+	d := testRoomDescription()
+	var buf bytes.Buffer
+	n, err := d.BinaryEncode(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, buf.Len(), n)
+
+	decoded := new(pctk.RoomDescription)
+	require.NoError(t, decoded.BinaryDecode(&buf))
+	assert.Equal(t, d, decoded)
+}
+
+func TestRoomDescription_Build(t *testing.T) {
+	room, err := testRoomDescription().Build()
+	require.NoError(t, err)
+	assert.Equal(t, pctk.NewResourceRef("resources", "backgrounds/hall"), room.Background)
+
+	door := room.GetScriptField("door").UserData.(*pctk.Object)
+	assert.Equal(t, room, door.Room)
+	assert.True(t, door.Class.Is(pctk.ObjectClassOpenable))
+	assert.Equal(t, door.States["closed"], door.State)
+	assert.Nil(t, door.States["gone"].Anim)
+
+	guard := room.GetScriptField("guard").UserData.(*pctk.Actor)
+	assert.Equal(t, "Guard", guard.Name)
+	assert.Equal(t, room, guard.Room)
+	assert.Equal(t, pctk.NewPos(200, 120), guard.ItemPosition())
+
+	assert.NotNil(t, room.GetScriptField("floor"))
+}
+
+func TestRoomDescription_BuildUnknownState(t *testing.T) {
+	d := testRoomDescription()
+	d.Objects[0].State = "broken"
+	_, err := d.Build()
+	assert.Error(t, err)
+}
+
+func TestRoomDescription_BuildConcaveWalkBox(t *testing.T) {
+	d := testRoomDescription()
+	d.WalkBoxes[0].Vertices = []pctk.Position{{X: 0, Y: 0}, {X: 4, Y: 0}, {X: 2, Y: 1}, {X: 4, Y: 4}}
+	_, err := d.Build()
+	assert.ErrorContains(t, err, "walkbox floor must be a convex polygon")
+}
@@ -39,6 +39,9 @@ const (
 	// ScriptEntityMusic is the type of a Music entity.
 	ScriptEntityMusic ScriptEntityType = "music"
 
+	// ScriptEntityNumber is the type of a plain number, passed to scripts as is.
+	ScriptEntityNumber ScriptEntityType = "number"
+
 	// ScriptEntityObject is the type of an Object entity.
 	ScriptEntityObject ScriptEntityType = "object"
 
@@ -69,6 +72,12 @@ const (
 	// ScriptEntityState is the type of an ObjectState entity.
 	ScriptEntityState ScriptEntityType = "state"
 
+	// ScriptEntityTimer is the type of a RoomTimer entity.
+	ScriptEntityTimer ScriptEntityType = "timer"
+
+	// ScriptEntityTrigger is the type of a Trigger entity.
+	ScriptEntityTrigger ScriptEntityType = "trigger"
+
 	// ScriptEntityWalkBox is the type of a Walkbox entity.
 	ScriptEntityWalkBox ScriptEntityType = "walkbox"
 )
@@ -26,6 +26,8 @@ func (s *Script) luaInit(app *App) {
 		s.lua.DeclareSentenceChoiceType()
 		s.lua.DeclareSizeType()
 		s.lua.DeclareSoundType()
+		s.lua.DeclareTimerType()
+		s.lua.DeclareTriggerType()
 		s.lua.DeclareWalkBoxType()
 
 		s.lua.DeclareExportFunction(func(exp ScriptNamedEntityValue) {
@@ -5,6 +5,7 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
 
 	rl "github.com/gen2brain/raylib-go/raylib"
 )
@@ -20,8 +21,47 @@ func NewSound(ref ResourceRef) *Sound {
 	return &Sound{ref: ref}
 }
 
-// Play plays the sound.
+// Play plays the sound at full volume and centered.
 func (s *Sound) Play(app *App) {
+	s.PlayAt(app, 1, 0)
+}
+
+// PlayAt plays the sound with the given volume, from 0 to 1, and stereo panning, from -1 (left) to
+// 1 (right).
+func (s *Sound) PlayAt(app *App, volume, pan float32) {
+	s.load(app)
+	s.SetLevels(volume, pan)
+	rl.PlaySound(s.track.raw)
+}
+
+// SetLevels changes the volume and the stereo panning of the sound, even while it is playing.
+func (s *Sound) SetLevels(volume, pan float32) {
+	if s.track == nil {
+		return
+	}
+	rl.SetSoundVolume(s.track.raw, volume)
+	rl.SetSoundPan(s.track.raw, raylibPan(pan))
+}
+
+// raylibPan converts a stereo panning from -1 (left) to 1 (right) into the one of raylib, which
+// pans from 1 (left) to 0 (right), being 0.5 the center.
+func raylibPan(pan float32) float32 {
+	return 0.5 - pan/2
+}
+
+// IsPlaying returns true if the sound is playing, false otherwise.
+func (s *Sound) IsPlaying() bool {
+	return s.track != nil && rl.IsSoundPlaying(s.track.raw)
+}
+
+// Stop stops the sound.
+func (s *Sound) Stop(app *App) {
+	if s.track != nil {
+		rl.StopSound(s.track.raw)
+	}
+}
+
+func (s *Sound) load(app *App) {
 	if s.track == nil {
 		track := app.res.LoadSound(s.ref)
 		if track == nil {
@@ -29,12 +69,117 @@ func (s *Sound) Play(app *App) {
 		}
 		s.track = track
 	}
-	rl.PlaySound(s.track.raw)
 }
 
-// Stop stops the sound.
-func (s *Sound) Stop(app *App) {
-	rl.StopSound(s.track.raw)
+// SoundEmitter is a sound that loops in a room while it is shown, like crickets or a waterfall. Its
+// volume and panning depend on where it is placed relative to the center of the camera.
+type SoundEmitter struct {
+	Sound   *Sound   // The sound played by the emitter.
+	Pos     Position // The position of the emitter in the room.
+	Radius  int      // The distance up to which the emitter is heard, or 0 to be heard everywhere.
+	Volume  float32  // The volume of the emitter when heard from its position.
+	Enabled bool     // Whether the emitter is playing while the room is shown.
+
+	stream  rl.Music // The stream that loops the sound while the emitter is playing.
+	playing bool
+}
+
+// NewSoundEmitter creates a new enabled sound emitter at full volume.
+func NewSoundEmitter(sound *Sound, pos Position) *SoundEmitter {
+	return &SoundEmitter{Sound: sound, Pos: pos, Volume: 1, Enabled: true}
+}
+
+// play plays the emitter with the given volume and panning, starting its loop if it was not playing.
+// It must be called every frame while the emitter is heard to keep the loop streaming.
+func (e *SoundEmitter) play(app *App, volume, pan float32) {
+	if !e.playing {
+		e.Sound.load(app)
+		e.stream = e.Sound.track.loop()
+		rl.PlayMusicStream(e.stream)
+		e.playing = true
+	}
+	rl.SetMusicVolume(e.stream, volume)
+	rl.SetMusicPan(e.stream, raylibPan(pan))
+	rl.UpdateMusicStream(e.stream)
+}
+
+// stop stops the emitter if it is playing, releasing its loop.
+func (e *SoundEmitter) stop() {
+	if e.playing {
+		rl.StopMusicStream(e.stream)
+		rl.UnloadMusicStream(e.stream)
+		e.playing = false
+	}
+}
+
+// levels returns the volume and the panning of the emitter heard by a listener at the given
+// position, with the given distance from its center to the left and right edges of the screen.
+func (e *SoundEmitter) levels(listener Positionf, halfWidth float32) (volume, pan float32) {
+	volume = e.Volume
+	if e.Radius > 0 {
+		dist := e.Pos.ToPosf().Distance(listener)
+		volume *= max(0, 1-dist/float32(e.Radius))
+	}
+	return volume, soundPan(e.Pos, listener, halfWidth)
+}
+
+// soundPan returns the stereo panning of a sound played at the given position, for a listener at
+// the given position and the given distance from its center to the edges of the screen.
+func soundPan(pos Position, listener Positionf, halfWidth float32) float32 {
+	if halfWidth <= 0 {
+		return 0
+	}
+	return max(-1, min(1, (float32(pos.X)-listener.X)/halfWidth))
+}
+
+// DeclareSoundEmitter declares a sound emitter in the room with the given name.
+func (r *Room) DeclareSoundEmitter(name string, e *SoundEmitter) {
+	if _, found := r.emitters[name]; found {
+		log.Fatalf("Sound emitter already declared: %s", name)
+	}
+	r.emitters[name] = e
+}
+
+// SoundEmitter returns the sound emitter of the room with the given name, or nil if not found.
+func (r *Room) SoundEmitter(name string) *SoundEmitter {
+	return r.emitters[name]
+}
+
+// soundListener returns the position of the room where sounds are heard from, which is the center of
+// the camera, and the distance from it to the left and right edges of the screen.
+func (v *Viewport) soundListener() (Positionf, float32) {
+	w, h := cameraVisibleSize(v.camzoom)
+	return v.campos.Add(NewPosf(w/2, h/2)), w / 2
+}
+
+// PlaySoundAt plays the sound panned by the position of the room it comes from.
+func (a *App) PlaySoundAt(sound *Sound, pos Position) {
+	listener, halfWidth := a.viewport.soundListener()
+	sound.PlayAt(a, 1, soundPan(pos, listener, halfWidth))
+}
+
+// processRoomSounds plays the enabled sound emitters of the room shown in the viewport, stopping the
+// ones of the room shown before, and updates their levels as the camera moves.
+func (a *App) processRoomSounds() {
+	room := a.viewport.Room
+	if a.audible != nil && a.audible != room {
+		for _, e := range a.audible.emitters {
+			e.stop()
+		}
+	}
+	a.audible = room
+	if room == nil {
+		return
+	}
+	listener, halfWidth := a.viewport.soundListener()
+	for _, e := range room.emitters {
+		if !e.Enabled {
+			e.stop()
+			continue
+		}
+		volume, pan := e.levels(listener, halfWidth)
+		e.play(a, volume, pan)
+	}
 }
 
 // SoundTrack source type
@@ -59,6 +204,13 @@ func LoadSoundFromFile(path string) *SoundTrack {
 	return sound
 }
 
+// loop returns a new stream of the sound data that repeats with no gap between plays.
+func (s *SoundTrack) loop() rl.Music {
+	music := rl.LoadMusicStreamFromMemory(strings.ToLower(string(s.format[:])), s.data, int32(len(s.data)))
+	music.Looping = true
+	return music
+}
+
 // BinaryEncode encodes the sound data to a binary stream. The format is:
 //   - [4]byte: data format
 //   - uint32: data length
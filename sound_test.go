@@ -0,0 +1,35 @@
+package pctk
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSoundEmitter_levels(t *testing.T) {
+	listener := NewPosf(160, 72)
+	waterfall := NewSoundEmitter(NewSound(ResourceRefNull), NewPos(160, 72))
+	waterfall.Volume = 0.8
+
+	volume, pan := waterfall.levels(listener, 160)
+	assert.Equal(t, float32(0.8), volume)
+	assert.Equal(t, float32(0), pan)
+
+	waterfall.Pos = NewPos(240, 72)
+	waterfall.Radius = 160
+	volume, pan = waterfall.levels(listener, 160)
+	assert.InDelta(t, 0.4, volume, 0.001)
+	assert.Equal(t, float32(0.5), pan)
+
+	waterfall.Pos = NewPos(-200, 72)
+	volume, pan = waterfall.levels(listener, 160)
+	assert.Equal(t, float32(0), volume)
+	assert.Equal(t, float32(-1), pan)
+}
+
+func TestSoundPan(t *testing.T) {
+	listener := NewPosf(160, 72)
+	assert.Equal(t, float32(-0.5), soundPan(NewPos(80, 100), listener, 160))
+	assert.Equal(t, float32(1), soundPan(NewPos(400, 100), listener, 160))
+	assert.Equal(t, float32(0), soundPan(NewPos(400, 100), listener, 0))
+}
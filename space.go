@@ -3,6 +3,7 @@ package pctk
 import (
 	"fmt"
 	"math"
+	"strings"
 
 	rl "github.com/gen2brain/raylib-go/raylib"
 )
@@ -355,3 +356,13 @@ const (
 	DirUp
 	DirDown
 )
+
+// ParseDirection parses the name of a direction, regardless of its case.
+func ParseDirection(name string) (Direction, error) {
+	for _, d := range []Direction{DirRight, DirLeft, DirUp, DirDown} {
+		if strings.EqualFold(d.String(), name) {
+			return d, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid direction %q", name)
+}
@@ -6,11 +6,21 @@ import (
 	rl "github.com/gen2brain/raylib-go/raylib"
 )
 
-// SpriteSheet represents a collection of sprites arranged in a grid-shaped sheet.
+// SpriteSheet represents a collection of sprites. By default, sprites are arranged in a
+// grid-shaped sheet where every sprite has the frame size. Alternatively, the sheet can be an atlas
+// where each sprite is located by its own SpriteFrame. In both cases, sprites are addressed by
+// column and row.
 type SpriteSheet struct {
 	raw       *rl.Image
 	tex       rl.Texture2D
 	frameSize Size
+	frames    [][]SpriteFrame
+}
+
+// SpriteFrame is the location of a sprite in an atlas sprite sheet.
+type SpriteFrame struct {
+	Rect   Rectangle // The rectangle of the sprite in the sheet image.
+	Offset Position  // The position of the rectangle relative to the top-left corner of the frame.
 }
 
 // LoadSpriteSheetFromFile loads a sprite sheet from a image file.
@@ -21,6 +31,37 @@ func LoadSpriteSheetFromFile(path string, frameSize Size) *SpriteSheet {
 	}
 }
 
+// NewSpriteSheetFromPNG creates a new sprite sheet from an image in PNG format.
+func NewSpriteSheetFromPNG(data []byte, frameSize Size) *SpriteSheet {
+	return &SpriteSheet{
+		raw:       rl.LoadImageFromMemory(".png", data, int32(len(data))),
+		frameSize: frameSize,
+	}
+}
+
+// AddRow adds a row of frames to the sprite sheet, turning it into an atlas. Once a row is added,
+// sprites are no longer located using the grid of the frame size.
+func (s *SpriteSheet) AddRow(frames ...SpriteFrame) *SpriteSheet {
+	s.frames = append(s.frames, frames)
+	return s
+}
+
+// Frame returns the location of the sprite at the given column and row.
+func (s *SpriteSheet) Frame(col, row uint) SpriteFrame {
+	if s.frames == nil {
+		return SpriteFrame{
+			Rect: Rectangle{
+				Pos:  Position{s.frameSize.W * int(col), s.frameSize.H * int(row)},
+				Size: s.frameSize,
+			},
+		}
+	}
+	if int(row) >= len(s.frames) || int(col) >= len(s.frames[row]) {
+		return SpriteFrame{}
+	}
+	return s.frames[row][col]
+}
+
 // Release releases the resources used by the sprite sheet.
 func (s *SpriteSheet) Release() {
 	rl.UnloadTexture(s.tex)
@@ -28,27 +69,75 @@ func (s *SpriteSheet) Release() {
 
 // DrawSprite draws a sprite from the sprite sheet at the given position.
 func (s *SpriteSheet) DrawSprite(col, row uint, pos Position, flip bool) {
-	src := Rectangle{
-		Pos: Position{
-			int(s.frameSize.W) * int(col),
-			int(s.frameSize.H) * int(row),
-		},
-		Size: s.frameSize,
+	s.DrawSpriteScaled(col, row, pos, flip, 1)
+}
+
+// DrawSpriteScaled draws a sprite from the sprite sheet at the given position, scaled by the given
+// factor. The position is the top-left corner of the scaled frame.
+func (s *SpriteSheet) DrawSpriteScaled(col, row uint, pos Position, flip bool, scale float32) {
+	s.DrawSpriteTinted(col, row, pos, flip, scale, White)
+}
+
+// DrawSpriteTinted draws a sprite from the sprite sheet like DrawSpriteScaled, tinted with the given
+// color.
+func (s *SpriteSheet) DrawSpriteTinted(col, row uint, pos Position, flip bool, scale float32, tint Color) {
+	frame := s.Frame(col, row)
+	if frame.Rect.Size.W == 0 || frame.Rect.Size.H == 0 {
+		return
 	}
+	src := frame.Rect
+	offset := frame.Offset
 	if flip {
 		src.Size = src.Size.FlipH()
+		offset.X = s.frameSize.W - frame.Offset.X - frame.Rect.Size.W
 	}
-	rl.DrawTextureRec(s.texture(), src.toRaylib(), pos.toRaylib(), rl.White)
+	dst := rl.NewRectangle(
+		float32(pos.X)+float32(offset.X)*scale,
+		float32(pos.Y)+float32(offset.Y)*scale,
+		float32(frame.Rect.Size.W)*scale,
+		float32(frame.Rect.Size.H)*scale,
+	)
+	rl.DrawTexturePro(s.texture(), src.toRaylib(), dst, rl.Vector2{}, 0, tint)
 }
 
 // BinaryEncode encodes the sprite sheet to a binary format. The encoded format is:
-// - uint16: the width of each sprite.
-// - uint16: the height of each sprite.
+// - uint16: the width of each frame.
+// - uint16: the height of each frame.
 // - uint32: the length of the image bytes.
 // - []byte: the image bytes in PNG format.
+// - uint16: the number of atlas rows, zero for grid-shaped sheets.
+// - for each row:
+//   - uint16: the number of frames.
+//   - for each frame, uint16 X, Y, width and height of the rectangle and int16 X and Y of the
+//     offset.
 func (s *SpriteSheet) BinaryEncode(w io.Writer) (int, error) {
 	bytes := rl.ExportImageToMemory(*s.raw, ".png")
-	return BinaryEncode(w, uint16(s.frameSize.W), uint16(s.frameSize.H), uint32(len(bytes)), bytes)
+	n, err := BinaryEncode(w,
+		uint16(s.frameSize.W), uint16(s.frameSize.H), uint32(len(bytes)), bytes,
+		uint16(len(s.frames)),
+	)
+	if err != nil {
+		return n, err
+	}
+	for _, row := range s.frames {
+		nn, err := BinaryEncode(w, uint16(len(row)))
+		n += nn
+		if err != nil {
+			return n, err
+		}
+		for _, f := range row {
+			nn, err := BinaryEncode(w,
+				uint16(f.Rect.Pos.X), uint16(f.Rect.Pos.Y),
+				uint16(f.Rect.Size.W), uint16(f.Rect.Size.H),
+				int16(f.Offset.X), int16(f.Offset.Y),
+			)
+			n += nn
+			if err != nil {
+				return n, err
+			}
+		}
+	}
+	return n, nil
 }
 
 // BinaryDecode decodes the sprite sheet from a binary format. See SpriteSheet.BinaryEncode for the
@@ -65,6 +154,31 @@ func (s *SpriteSheet) BinaryDecode(r io.Reader) error {
 	}
 	s.frameSize = Size{int(w), int(h)}
 	s.raw = rl.LoadImageFromMemory(".png", bytes, int32(len(bytes)))
+
+	var rows uint16
+	if err := BinaryDecode(r, &rows); err != nil {
+		return err
+	}
+	s.frames = nil
+	for i := uint16(0); i < rows; i++ {
+		var count uint16
+		if err := BinaryDecode(r, &count); err != nil {
+			return err
+		}
+		row := make([]SpriteFrame, count)
+		for j := range row {
+			var x, y, w, h uint16
+			var ox, oy int16
+			if err := BinaryDecode(r, &x, &y, &w, &h, &ox, &oy); err != nil {
+				return err
+			}
+			row[j] = SpriteFrame{
+				Rect:   Rectangle{Pos: Position{int(x), int(y)}, Size: Size{int(w), int(h)}},
+				Offset: Position{int(ox), int(oy)},
+			}
+		}
+		s.frames = append(s.frames, row)
+	}
 	return nil
 }
 
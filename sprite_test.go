@@ -0,0 +1,25 @@
+package pctk_test
+
+import (
+	"testing"
+
+	"github.com/apoloval/pctk"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSpriteSheet_AtlasFrame(t *testing.T) {
+	walk := []pctk.SpriteFrame{
+		{Rect: pctk.NewRect(0, 0, 10, 20), Offset: pctk.NewPos(3, 4)},
+		{Rect: pctk.NewRect(11, 0, 12, 18), Offset: pctk.NewPos(2, 6)},
+	}
+	idle := []pctk.SpriteFrame{
+		{Rect: pctk.NewRect(24, 0, 8, 8)},
+	}
+	sheet := new(pctk.SpriteSheet).AddRow(walk...).AddRow(idle...)
+
+	assert.Equal(t, walk[0], sheet.Frame(0, 0))
+	assert.Equal(t, walk[1], sheet.Frame(1, 0))
+	assert.Equal(t, idle[0], sheet.Frame(0, 1))
+	assert.Equal(t, pctk.SpriteFrame{}, sheet.Frame(1, 1))
+	assert.Equal(t, pctk.SpriteFrame{}, sheet.Frame(0, 2))
+}
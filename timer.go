@@ -0,0 +1,101 @@
+package pctk
+
+import (
+	"log"
+	"slices"
+	"time"
+)
+
+// RoomTimer is a script function called after some time while its room is shown, either once or
+// repeatedly. Timers only run while their room is shown, and they are cancelled when the room is
+// exited.
+type RoomTimer struct {
+	Callback *ScriptCallback // The function called when the timer expires.
+	Interval time.Duration   // The time until the timer expires, counted again if it repeats.
+	Repeat   bool            // Whether the timer starts over once expired.
+
+	next      time.Time // When the timer expires next time.
+	running   Future    // The call to the function in progress, if any.
+	cancelled bool
+}
+
+// Cancel cancels the timer, so its function is not called anymore.
+func (t *RoomTimer) Cancel() {
+	t.cancelled = true
+}
+
+// StartTimer starts the given timer in the room, counting from the given instant.
+func (r *Room) StartTimer(t *RoomTimer, now time.Time) {
+	t.next = now.Add(t.Interval)
+	r.timers = append(r.timers, t)
+}
+
+// CancelTimers cancels all the timers of the room.
+func (r *Room) CancelTimers() {
+	for _, t := range r.timers {
+		t.Cancel()
+	}
+	r.timers = nil
+}
+
+// expiredTimers returns the timers of the room that expired at the given instant, scheduling again
+// the ones that repeat and removing the others. A repeating timer still running its function from a
+// previous expiration is skipped until the next one.
+func (r *Room) expiredTimers(now time.Time) []*RoomTimer {
+	var expired []*RoomTimer
+	for _, t := range r.timers {
+		if t.cancelled || now.Before(t.next) {
+			continue
+		}
+		if t.Repeat {
+			for !now.Before(t.next) {
+				t.next = t.next.Add(max(t.Interval, time.Millisecond))
+			}
+		} else {
+			t.cancelled = true
+		}
+		if t.running == nil || t.running.IsCompleted() {
+			expired = append(expired, t)
+		}
+	}
+	r.timers = slices.DeleteFunc(r.timers, func(t *RoomTimer) bool {
+		return t.cancelled
+	})
+	return expired
+}
+
+// processRoomUpdate calls the update function of the room shown in the viewport with the time
+// elapsed since the previous call, and the functions of its expired timers.
+func (a *App) processRoomUpdate() {
+	room := a.viewport.Room
+	if room == nil || room.exiting {
+		return
+	}
+	now := time.Now()
+	if cb := room.FindCallback("update"); cb != nil && (room.updating == nil || room.updating.IsCompleted()) {
+		var dt time.Duration
+		if !room.updated.IsZero() {
+			dt = now.Sub(room.updated)
+		}
+		room.updated = now
+		room.updating = RecoverWithValue(
+			cb.Invoke([]ScriptEntityValue{{
+				Type:     ScriptEntityNumber,
+				UserData: float64(dt.Milliseconds()),
+			}}),
+			func(err error) any {
+				log.Printf("Failed to call room update function: %v", err)
+				return nil
+			},
+		)
+	}
+	for _, t := range room.expiredTimers(now) {
+		t.running = RecoverWithValue(
+			t.Callback.Invoke(nil),
+			func(err error) any {
+				log.Printf("Failed to call room timer function: %v", err)
+				return nil
+			},
+		)
+	}
+}
@@ -0,0 +1,64 @@
+package pctk
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoom_expiredTimers(t *testing.T) {
+	room := NewRoom()
+	start := time.Now()
+	clock := &RoomTimer{Interval: time.Second, Repeat: true}
+	squawk := &RoomTimer{Interval: 2500 * time.Millisecond}
+	room.StartTimer(clock, start)
+	room.StartTimer(squawk, start)
+
+	assert.Empty(t, room.expiredTimers(start.Add(500*time.Millisecond)))
+	assert.Equal(t, []*RoomTimer{clock}, room.expiredTimers(start.Add(time.Second)))
+	assert.Empty(t, room.expiredTimers(start.Add(1500*time.Millisecond)))
+
+	// Expirations missed by a long frame are not called several times.
+	assert.Equal(t, []*RoomTimer{clock, squawk}, room.expiredTimers(start.Add(3200*time.Millisecond)))
+	assert.Equal(t, []*RoomTimer{clock}, room.timers)
+
+	// Repeating timers still running are skipped.
+	clock.running = NewPromise()
+	assert.Empty(t, room.expiredTimers(start.Add(4*time.Second)))
+	clock.running = AlreadySucceeded(nil)
+	assert.Equal(t, []*RoomTimer{clock}, room.expiredTimers(start.Add(5*time.Second)))
+
+	clock.Cancel()
+	assert.Empty(t, room.expiredTimers(start.Add(6*time.Second)))
+	assert.Empty(t, room.timers)
+}
+
+func TestRoom_CancelTimers(t *testing.T) {
+	room := NewRoom()
+	start := time.Now()
+	clock := &RoomTimer{Interval: time.Second, Repeat: true}
+	room.StartTimer(clock, start)
+
+	room.CancelTimers()
+	assert.Empty(t, room.expiredTimers(start.Add(2*time.Second)))
+
+	// New timers run normally after the room cancelled the previous ones.
+	room.StartTimer(&RoomTimer{Interval: time.Second}, start)
+	assert.Len(t, room.expiredTimers(start.Add(2*time.Second)), 1)
+	assert.True(t, clock.cancelled)
+}
+
+func TestViewport_ActivateRoomCancelsTimers(t *testing.T) {
+	bar, street := NewRoom(), NewRoom()
+	clock := &RoomTimer{Interval: time.Second, Repeat: true}
+	bar.StartTimer(clock, time.Now())
+
+	var v Viewport
+	v.Room = bar
+	v.ActivateRoom(street, nil, Transition{})
+	assert.True(t, clock.cancelled)
+	assert.True(t, bar.exiting)
+	assert.False(t, street.exiting)
+	assert.Equal(t, street, v.Room)
+}
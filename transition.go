@@ -0,0 +1,279 @@
+package pctk
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// TransitionEffect is a visual effect that covers or uncovers the viewport.
+type TransitionEffect int
+
+const (
+	TransitionCut       TransitionEffect = iota // No effect at all.
+	TransitionFade                              // Fade to or from black.
+	TransitionCrossfade                         // Blend the last frame shown into the next one.
+	TransitionIris                              // A circle that closes or opens around a point.
+	TransitionDissolve                          // Random blocks of black that appear or disappear.
+	TransitionWipe                              // A box that shrinks to or grows from the center.
+)
+
+var transitionEffectNames = []string{"cut", "fade", "crossfade", "iris", "dissolve", "wipe"}
+
+// ParseTransitionEffect parses the name of a transition effect.
+func ParseTransitionEffect(name string) (TransitionEffect, error) {
+	for i, n := range transitionEffectNames {
+		if strings.EqualFold(n, name) {
+			return TransitionEffect(i), nil
+		}
+	}
+	return TransitionCut, fmt.Errorf("unknown transition effect %q", name)
+}
+
+// String returns the name of the transition effect.
+func (e TransitionEffect) String() string {
+	if int(e) < len(transitionEffectNames) {
+		return transitionEffectNames[e]
+	}
+	return fmt.Sprintf("TransitionEffect(%d)", int(e))
+}
+
+// Transition describes how the viewport changes from one room to another. The first half of the
+// duration covers the old room with the effect, and the second half uncovers the new one.
+type Transition struct {
+	Effect   TransitionEffect
+	Duration time.Duration
+	Center   *Actor // The actor the iris is centered on, or nil to center it in the viewport.
+}
+
+// screenCover is a transition effect drawn over the viewport, from one amount of coverage to
+// another over a period of time. When fully covered, the viewport is not visible at all.
+type screenCover struct {
+	effect   TransitionEffect
+	center   *Actor
+	from, to float32
+	start    time.Time
+	duration time.Duration
+	done     *Promise
+}
+
+// amount returns the coverage of the viewport at the given instant, and whether the effect is
+// finished.
+func (c *screenCover) amount(now time.Time) (float32, bool) {
+	t := float32(1)
+	if c.duration > 0 {
+		t = min(1, float32(now.Sub(c.start))/float32(c.duration))
+	}
+	return c.from + (c.to-c.from)*t, t >= 1
+}
+
+// screenTint is a color drawn over the viewport, changing from one color to another over a period
+// of time.
+type screenTint struct {
+	from, to Color
+	start    time.Time
+	duration time.Duration
+	done     *Promise
+}
+
+// color returns the color of the tint at the given instant, and whether the change is finished.
+func (t *screenTint) color(now time.Time) (Color, bool) {
+	p := float32(1)
+	if t.duration > 0 {
+		p = min(1, float32(now.Sub(t.start))/float32(t.duration))
+	}
+	lerp := func(a, b uint8) uint8 {
+		return uint8(float32(a) + (float32(b)-float32(a))*p)
+	}
+	return Color{
+		R: lerp(t.from.R, t.to.R),
+		G: lerp(t.from.G, t.to.G),
+		B: lerp(t.from.B, t.to.B),
+		A: lerp(t.from.A, t.to.A),
+	}, p >= 1
+}
+
+// TransitionOut covers the viewport with the given effect over the given duration. The viewport
+// remains covered until TransitionIn is called. Iris effects are centered on the given actor if it
+// is in the room, or in the viewport otherwise. The returned future is completed when the viewport
+// is fully covered. If the viewport is already fully covered, it remains as is.
+func (v *Viewport) TransitionOut(effect TransitionEffect, duration time.Duration, center *Actor) Future {
+	if v.isCovered() {
+		return AlreadySucceeded(nil)
+	}
+	done := NewPromise()
+	cover := &screenCover{
+		effect:   effect,
+		center:   center,
+		from:     0,
+		to:       1,
+		start:    time.Now(),
+		duration: duration,
+		done:     done,
+	}
+	if effect == TransitionCrossfade {
+		// The last frame of the room is captured first, then shown as is until the transition in.
+		cover.duration = 0
+		v.capture = cover
+	} else {
+		v.setCover(cover)
+	}
+	return done
+}
+
+// TransitionIn uncovers the viewport with the given effect over the given duration. Iris effects
+// are centered on the given actor if it is in the room, or in the viewport otherwise. The returned
+// future is completed when the viewport is fully visible.
+func (v *Viewport) TransitionIn(effect TransitionEffect, duration time.Duration, center *Actor) Future {
+	done := NewPromise()
+	v.setCover(&screenCover{
+		effect:   effect,
+		center:   center,
+		from:     1,
+		to:       0,
+		start:    time.Now(),
+		duration: duration,
+		done:     done,
+	})
+	return done
+}
+
+// TintTo changes the color drawn over the viewport to the given one over the given duration. A
+// transparent color removes the tint. The returned future is completed when the color is reached.
+func (v *Viewport) TintTo(color Color, duration time.Duration) Future {
+	from := Blank
+	if v.tint != nil {
+		from, _ = v.tint.color(time.Now())
+		if !v.tint.done.IsCompleted() {
+			v.tint.done.Complete()
+		}
+	}
+	v.tint = &screenTint{
+		from:     from,
+		to:       color,
+		start:    time.Now(),
+		duration: duration,
+		done:     NewPromise(),
+	}
+	return v.tint.done
+}
+
+// isCovered returns true if the viewport is fully covered by a transition effect.
+func (v *Viewport) isCovered() bool {
+	return v.cover != nil && v.cover.to == 1 && v.cover.done.IsCompleted()
+}
+
+func (v *Viewport) setCover(cover *screenCover) {
+	if v.cover != nil && !v.cover.done.IsCompleted() {
+		v.cover.done.Complete()
+	}
+	v.cover = cover
+}
+
+// captureSnapshot copies the room already drawn in the screen into the snapshot texture, to be
+// blended by crossfades. The room is not drawn again, so it does not advance twice in the frame.
+func (v *Viewport) captureSnapshot() {
+	rl.DrawRenderBatchActive()
+	img := rl.LoadImageFromScreen()
+	defer rl.UnloadImage(img)
+
+	// The screen image may be larger than the window in high DPI displays.
+	scale := float32(img.Width) / float32(rl.GetScreenWidth())
+	rl.ImageCrop(img, rl.NewRectangle(0, 0, ScreenWidth*v.screenZoom*scale, ViewportHeight*v.screenZoom*scale))
+	if rl.IsTextureReady(v.snapshot) {
+		rl.UnloadTexture(v.snapshot)
+	}
+	v.snapshot = rl.LoadTextureFromImage(img)
+}
+
+// processFrameCapture captures the room drawn in this frame for a pending crossfade, if any.
+func (v *Viewport) processFrameCapture() {
+	if v.capture != nil {
+		v.captureSnapshot()
+		v.setCover(v.capture)
+		v.capture = nil
+	}
+}
+
+// processFrameEffects draws the tint and the transition effects over the viewport.
+func (v *Viewport) processFrameEffects() {
+	now := time.Now()
+	if v.tint != nil {
+		color, finished := v.tint.color(now)
+		if finished && !v.tint.done.IsCompleted() {
+			v.tint.done.Complete()
+		}
+		rl.DrawRectangle(0, 0, int32(ScreenWidth*v.screenZoom), int32(ViewportHeight*v.screenZoom), color)
+		if finished && color.A == 0 {
+			v.tint = nil
+		}
+	}
+
+	if v.cover != nil {
+		amount, finished := v.cover.amount(now)
+		if finished && !v.cover.done.IsCompleted() {
+			v.cover.done.Complete()
+		}
+		v.drawCover(amount)
+		if finished && amount == 0 {
+			v.cover = nil
+		}
+	}
+}
+
+// drawCover draws the current transition effect covering the given amount of the viewport, in
+// screen coordinates.
+func (v *Viewport) drawCover(amount float32) {
+	w, h := ScreenWidth*v.screenZoom, ViewportHeight*v.screenZoom
+	switch v.cover.effect {
+	case TransitionFade:
+		rl.DrawRectangle(0, 0, int32(w), int32(h), rl.Fade(Black, amount))
+	case TransitionCrossfade:
+		if !rl.IsTextureReady(v.snapshot) {
+			return
+		}
+		src := rl.NewRectangle(0, 0, float32(v.snapshot.Width), float32(v.snapshot.Height))
+		dst := rl.NewRectangle(0, 0, w, h)
+		rl.DrawTexturePro(v.snapshot, src, dst, rl.NewVector2(0, 0), 0, rl.Fade(White, amount))
+	case TransitionIris:
+		center := rl.NewVector2(w/2, h/2)
+		if actor := v.cover.center; actor != nil && actor.Room == v.Room {
+			center = v.camera.WorldToScreenPosition(actor.pos.ToPos()).toRaylib()
+		}
+		radius := float32(0)
+		for _, corner := range []rl.Vector2{{}, {X: w}, {Y: h}, {X: w, Y: h}} {
+			radius = max(radius, rl.Vector2Distance(center, corner))
+		}
+		rl.DrawRing(center, radius*(1-amount), radius+1, 0, 360, 64, Black)
+	case TransitionDissolve:
+		cell := 4 * v.screenZoom
+		for j := 0; float32(j)*cell < h; j++ {
+			for i := 0; float32(i)*cell < w; i++ {
+				if dissolveThreshold(i, j) < amount {
+					rl.DrawRectangle(int32(float32(i)*cell), int32(float32(j)*cell), int32(cell), int32(cell), Black)
+				}
+			}
+		}
+	case TransitionWipe:
+		hw, hh := w*(1-amount)/2, h*(1-amount)/2
+		left, right := int32(w/2-hw), int32(math.Ceil(float64(w/2+hw)))
+		top, bottom := int32(h/2-hh), int32(math.Ceil(float64(h/2+hh)))
+		rl.DrawRectangle(0, 0, int32(w), top, Black)
+		rl.DrawRectangle(0, bottom, int32(w), int32(h)-bottom, Black)
+		rl.DrawRectangle(0, top, left, bottom-top, Black)
+		rl.DrawRectangle(right, top, int32(w)-right, bottom-top, Black)
+	}
+}
+
+// dissolveThreshold returns the coverage from which the block at the given column and row is
+// covered by a dissolve effect. It looks random but it is always the same for the same block.
+func dissolveThreshold(i, j int) float32 {
+	h := uint32(i)*0x9E3779B1 ^ uint32(j)*0x85EBCA77
+	h ^= h >> 15
+	h *= 0x2C1B3C6D
+	h ^= h >> 12
+	return float32(h%1024) / 1024
+}
@@ -0,0 +1,66 @@
+package pctk
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTransitionEffect(t *testing.T) {
+	for _, effect := range []TransitionEffect{
+		TransitionCut, TransitionFade, TransitionCrossfade, TransitionIris, TransitionDissolve, TransitionWipe,
+	} {
+		parsed, err := ParseTransitionEffect(effect.String())
+		require.NoError(t, err)
+		assert.Equal(t, effect, parsed)
+	}
+	parsed, err := ParseTransitionEffect("Iris")
+	require.NoError(t, err)
+	assert.Equal(t, TransitionIris, parsed)
+
+	_, err = ParseTransitionEffect("spin")
+	assert.Error(t, err)
+}
+
+func TestScreenCover_amount(t *testing.T) {
+	start := time.Now()
+	cover := screenCover{from: 1, to: 0, start: start, duration: time.Second}
+
+	amount, finished := cover.amount(start.Add(250 * time.Millisecond))
+	assert.Equal(t, float32(0.75), amount)
+	assert.False(t, finished)
+
+	amount, finished = cover.amount(start.Add(2 * time.Second))
+	assert.Equal(t, float32(0), amount)
+	assert.True(t, finished)
+}
+
+func TestScreenTint_color(t *testing.T) {
+	start := time.Now()
+	tint := screenTint{from: Blank, to: Color{R: 200, G: 100, B: 0, A: 100}, start: start, duration: time.Second}
+
+	color, finished := tint.color(start.Add(500 * time.Millisecond))
+	assert.Equal(t, Color{R: 100, G: 50, B: 0, A: 50}, color)
+	assert.False(t, finished)
+
+	color, finished = tint.color(start.Add(time.Second))
+	assert.Equal(t, Color{R: 200, G: 100, B: 0, A: 100}, color)
+	assert.True(t, finished)
+}
+
+func TestDissolveThreshold(t *testing.T) {
+	covered := 0
+	for j := 0; j < 36; j++ {
+		for i := 0; i < 80; i++ {
+			th := dissolveThreshold(i, j)
+			assert.GreaterOrEqual(t, th, float32(0))
+			assert.Less(t, th, float32(1))
+			if th < 0.5 {
+				covered++
+			}
+		}
+	}
+	assert.InDelta(t, 80*36/2, covered, 80*36/10, "half of the blocks must be covered halfway")
+}
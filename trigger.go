@@ -0,0 +1,142 @@
+package pctk
+
+import (
+	"fmt"
+	"log"
+	"slices"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Trigger is an area of a room that fires callbacks when actors enter or leave it. Unlike walkboxes,
+// triggers do not need to be convex, and they do not affect where actors can walk.
+type Trigger struct {
+	triggerID string
+	vertices  []Positionf
+	callbacks []*ScriptCallback
+	room      *Room
+}
+
+// NewTrigger creates a new trigger with the area of the polygon formed by the given vertices. It
+// panics if there are less than three vertices.
+func NewTrigger(vertices []Position) *Trigger {
+	if len(vertices) < 3 {
+		log.Panicf("trigger must have at least 3 vertices: %v", vertices)
+	}
+	verticesf := make([]Positionf, len(vertices))
+	for i, v := range vertices {
+		verticesf[i] = v.ToPosf()
+	}
+	return &Trigger{vertices: verticesf}
+}
+
+// NewTriggerRect creates a new trigger with the area of the given rectangle.
+func NewTriggerRect(r Rectangle) *Trigger {
+	return NewTrigger([]Position{
+		r.Pos,
+		NewPos(r.RightEdge(), r.TopEdge()),
+		NewPos(r.RightEdge(), r.BottomEdge()),
+		NewPos(r.LeftEdge(), r.BottomEdge()),
+	})
+}
+
+// DeclareCallback declares a callback in the trigger.
+func (t *Trigger) DeclareCallback(cb *ScriptCallback) error {
+	for _, c := range t.callbacks {
+		if c.Name == cb.Name {
+			return fmt.Errorf("callback '%s' already declared", cb.Name)
+		}
+	}
+	t.callbacks = append(t.callbacks, cb)
+	return nil
+}
+
+// FindCallback finds a callback in the trigger by name.
+func (t *Trigger) FindCallback(name string) *ScriptCallback {
+	for _, c := range t.callbacks {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// containsPoint checks if the provided position is in the area of the trigger.
+func (t *Trigger) containsPoint(p Positionf) bool {
+	return polygonContains(t.vertices, p)
+}
+
+// Draws the edges of the trigger.
+func (t *Trigger) draw() {
+	numVertices := len(t.vertices)
+	for i := 0; i < numVertices; i++ {
+		p1 := t.vertices[i]
+		p2 := t.vertices[(i+1)%numVertices]
+		rl.DrawLineEx(p1.toRaylib(), p2.toRaylib(), 1.2, rl.NewColor(0xFF, 0x55, 0xFF, 0x7D))
+	}
+}
+
+// zone is an area of a room whose enter and leave callbacks are fired when actors cross into or out
+// of it.
+type zone interface {
+	ScriptCallbackReceiver
+	containsPoint(p Positionf) bool
+}
+
+// zonesAt returns the walkboxes and triggers of the room that contain the given position.
+func (r *Room) zonesAt(p Positionf) []zone {
+	var zones []zone
+	if r.wbmatrix != nil {
+		for _, wb := range r.wbmatrix.walkBoxes {
+			if wb.containsPoint(p) {
+				zones = append(zones, wb)
+			}
+		}
+	}
+	for _, t := range r.triggers {
+		if t.containsPoint(p) {
+			zones = append(zones, t)
+		}
+	}
+	return zones
+}
+
+// processZoneEvents fires the leave and enter callbacks of the walkboxes and triggers of the current
+// room crossed by its actors since the previous frame. Actors that were just put in the room or
+// relocated in it do not fire any callback until they move.
+func (a *App) processZoneEvents() {
+	room := a.viewport.Room
+	if room == nil {
+		return
+	}
+	for _, actor := range room.actors {
+		zones := room.zonesAt(actor.pos)
+		if actor.zonesRoom != room {
+			actor.zones, actor.zonesRoom = zones, room
+			continue
+		}
+		for _, z := range actor.zones {
+			if !slices.Contains(zones, z) {
+				invokeZoneCallback(z, "leave", actor)
+			}
+		}
+		for _, z := range zones {
+			if !slices.Contains(actor.zones, z) {
+				invokeZoneCallback(z, "enter", actor)
+			}
+		}
+		actor.zones = zones
+	}
+}
+
+func invokeZoneCallback(z zone, name string, actor *Actor) {
+	cb := z.FindCallback(name)
+	if cb == nil {
+		return
+	}
+	args := []ScriptEntityValue{{Type: ScriptEntityActor, UserData: actor}}
+	RecoverWithValue(cb.Invoke(args), func(err error) any {
+		log.Printf("Failed to call %s callback: %v", name, err)
+		return nil
+	})
+}
@@ -0,0 +1,46 @@
+package pctk
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoom_zonesAt(t *testing.T) {
+	room := NewRoom()
+	floor := NewWalkBox("floor", []Position{{0, 100}, {320, 100}, {320, 140}, {0, 140}}, 1)
+	room.DeclareWalkBoxMatrix([]*WalkBox{floor})
+	trap := NewTriggerRect(NewRect(100, 90, 20, 20))
+	room.DeclareTrigger("trap", trap)
+
+	assert.Equal(t, []zone{floor}, room.zonesAt(NewPosf(50, 120)))
+	assert.Equal(t, []zone{floor, trap}, room.zonesAt(NewPosf(110, 105)))
+	assert.Equal(t, []zone{trap}, room.zonesAt(NewPosf(110, 95)))
+	assert.Empty(t, room.zonesAt(NewPosf(200, 50)))
+
+	assert.Equal(t, &ScriptEntityValue{Type: ScriptEntityTrigger, UserData: trap}, room.GetScriptField("trap"))
+}
+
+func TestApp_processZoneEventsAfterLocate(t *testing.T) {
+	room := NewRoom()
+	floor := NewWalkBox("floor", []Position{{0, 100}, {320, 100}, {320, 140}, {0, 140}}, 1)
+	room.DeclareWalkBoxMatrix([]*WalkBox{floor})
+	trap := NewTriggerRect(NewRect(100, 90, 20, 20))
+	room.DeclareTrigger("trap", trap)
+	app := &App{}
+	app.viewport.Room = room
+
+	actor := NewActor("actor")
+	room.PutActor(actor)
+	actor.Locate(room, NewPos(50, 120), DirRight)
+	app.processZoneEvents()
+	assert.Equal(t, []zone{floor}, actor.zones)
+
+	// Relocating the actor in the same room starts over, as if it was just put there.
+	actor.Locate(room, NewPos(110, 95), DirRight)
+	assert.Nil(t, actor.zones)
+	assert.Nil(t, actor.zonesRoom)
+	app.processZoneEvents()
+	assert.Equal(t, []zone{trap}, actor.zones)
+	assert.Equal(t, room, actor.zonesRoom)
+}
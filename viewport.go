@@ -3,6 +3,7 @@ package pctk
 import (
 	"fmt"
 	"log"
+	"time"
 
 	rl "github.com/gen2brain/raylib-go/raylib"
 )
@@ -32,17 +33,30 @@ type ViewportEventHandler func(ViewportEvent)
 type Viewport struct {
 	Room *Room
 
-	camera    Camera
-	camtarget int
-	follow    *Actor
-	hover     RoomItem
-	dialogs   []Dialog
-	handlers  []ViewportEventHandler
+	camera     Camera
+	campos     Positionf // The top-left corner of the visible area of the room.
+	camtarget  Positionf // The position the camera is scrolling to.
+	camzoom    float32   // The zoom of the camera in the room.
+	screenZoom float32   // The zoom of the screen, applied to the camera on top of the room zoom.
+	deadzone   Size      // The area around the screen center where followed actors move freely.
+	move       *cameraMove
+	shake      *cameraShake
+	cover      *screenCover
+	capture    *screenCover
+	snapshot   rl.Texture2D
+	tint       *screenTint
+	follow     *Actor
+	hover      RoomItem
+	dialogs    []Dialog
+	editor     walkBoxEditor
+	handlers   []ViewportEventHandler
 }
 
 // Init initializes the viewport with the given camera.
 func (v *Viewport) Init(cam Camera) {
 	v.camera = cam
+	v.camzoom = 1
+	v.screenZoom = cam.Zoom()
 }
 
 // SubscribeEventHandler subscribes the given handler to the viewport events.
@@ -52,9 +66,15 @@ func (v *Viewport) SubscribeEventHandler(handler ViewportEventHandler) {
 
 // ActivateRoom sets the given room as the active room in the viewport. It will call the exit and
 // enter functions of the previous and new rooms, respectively, represented in the returned future.
-func (v *Viewport) ActivateRoom(room *Room, entrance *Object) Future {
+// The old room is covered and the new one is uncovered with the given transition, which the
+// returned future also waits for.
+func (v *Viewport) ActivateRoom(room *Room, entrance *Object, tr Transition) Future {
 	var job Future
 	if prev := v.Room; prev != nil {
+		// The room stops updating before it is exited, so its timers and update function do not
+		// run while the exit function does.
+		prev.CancelTimers()
+		prev.exiting = true
 		if cb := prev.FindCallback("exit"); cb != nil {
 			job = RecoverWithValue(
 				cb.Invoke(nil),
@@ -65,11 +85,38 @@ func (v *Viewport) ActivateRoom(room *Room, entrance *Object) Future {
 			)
 		}
 	}
+	if tr.Effect != TransitionCut {
+		job = Continue(job, func(a any) Future {
+			return v.TransitionOut(tr.Effect, tr.Duration/2, tr.Center)
+		})
+	}
 	job = Continue(job, func(a any) Future {
+		follow := v.follow
+		if prev := v.Room; prev != nil {
+			prev.CancelTimers()
+		}
+		room.updated = time.Time{}
+		room.exiting = false
 		v.Room = room
 		v.CameraCancelMovement()
+		v.camzoom = room.Zoom
+		if follow != nil && follow.Room == room {
+			// The followed actor moved to the new room, so the camera keeps following it,
+			// centered on where it stands.
+			w, h := cameraVisibleSize(v.camzoom)
+			v.follow = follow
+			v.campos = v.clampCamera(follow.pos.Sub(NewPosf(w/2, h/2)), v.camzoom)
+			v.camtarget = v.campos
+		}
 		return AlreadySucceeded(nil)
 	})
+	var uncovered Future = AlreadySucceeded(nil)
+	if tr.Effect != TransitionCut {
+		job = Continue(job, func(a any) Future {
+			uncovered = v.TransitionIn(tr.Effect, tr.Duration/2, tr.Center)
+			return AlreadySucceeded(nil)
+		})
+	}
 	if cb := room.FindCallback("enter"); cb != nil {
 		job = Continue(job, func(a any) Future {
 			var args []ScriptEntityValue
@@ -88,13 +135,26 @@ func (v *Viewport) ActivateRoom(room *Room, entrance *Object) Future {
 			)
 		})
 	}
-	return job
+	return Continue(job, func(a any) Future {
+		return uncovered
+	})
 }
 
-// CameraCancelMovement cancels the camera movement.
+// CameraCancelMovement cancels the camera movement. The camera stops where it is, and any pending
+// pan or zoom is completed.
 func (v *Viewport) CameraCancelMovement() {
 	v.follow = nil
-	v.camtarget = int(v.camera.Target().X)
+	v.camtarget = v.campos
+	if v.move != nil {
+		v.move.done.Complete()
+		v.move = nil
+	}
+}
+
+// CameraDeadZone sets the size of the area around the center of the screen where actors followed
+// by the camera can move without scrolling it.
+func (v *Viewport) CameraDeadZone(size Size) {
+	v.deadzone = size
 }
 
 // CameraFollowActor makes the camera follow the given actor.
@@ -102,36 +162,120 @@ func (v *Viewport) CameraFollowActor(actor *Actor) error {
 	if actor.Room != v.Room {
 		return fmt.Errorf("Actor %s is not in the room", actor.Name)
 	}
+	v.CameraCancelMovement()
 	v.follow = actor
 	return nil
 }
 
-// CameraMoveTo moves the camera to the given position.
-func (v *Viewport) CameraMoveTo(pos int) {
-	v.camtarget = pos
-	v.camera = v.camera.WithTarget(NewPos(pos, 0))
-	v.follow = nil
+// CameraMoveTo moves the camera to the given position of the room at once.
+func (v *Viewport) CameraMoveTo(pos Position) {
+	v.CameraCancelMovement()
+	v.campos = v.clampCamera(pos.ToPosf(), v.camzoom)
+	v.camtarget = v.campos
 }
 
 // CameraOnLeftEdge puts the camera on the left edge of the room.
 func (v *Viewport) CameraOnLeftEdge() {
-	v.CameraMoveTo(0)
+	v.CameraMoveTo(NewPos(0, int(v.campos.Y)))
 }
 
 // CameraOnRightEdge puts the camera on the right edge of the room.
 func (v *Viewport) CameraOnRightEdge() {
-	v.CameraMoveTo(v.Room.Rect().Size.W - ScreenWidth)
+	v.CameraMoveTo(NewPos(v.Room.Rect().Size.W, int(v.campos.Y)))
+}
+
+// CameraPanTo moves the camera to the given position of the room, easing the movement over the
+// given duration. The returned future is completed when the camera arrives.
+func (v *Viewport) CameraPanTo(pos Position, duration time.Duration) Future {
+	return v.cameraMoveTo(v.clampCamera(pos.ToPosf(), v.camzoom), v.camzoom, duration)
+}
+
+// CameraZoomTo changes the zoom of the camera, easing the change over the given duration and
+// keeping the center of the screen in place. The returned future is completed when the zoom is
+// reached. The zoom is reset to the one of the room when another room is activated.
+func (v *Viewport) CameraZoomTo(zoom float32, duration time.Duration) Future {
+	if zoom <= 0 {
+		return AlreadyFailed(fmt.Errorf("invalid camera zoom %v", zoom))
+	}
+	fromW, fromH := cameraVisibleSize(v.camzoom)
+	toW, toH := cameraVisibleSize(zoom)
+	center := v.campos.Add(NewPosf(fromW/2, fromH/2))
+	pos := v.clampCamera(center.Sub(NewPosf(toW/2, toH/2)), zoom)
+	return v.cameraMoveTo(pos, zoom, duration)
+}
+
+// CameraShake shakes the camera with the given intensity in pixels, fading out over the given
+// duration. The returned future is completed when the camera stops shaking.
+func (v *Viewport) CameraShake(intensity float32, duration time.Duration) Future {
+	if v.shake != nil {
+		v.shake.done.Complete()
+	}
+	v.shake = &cameraShake{
+		intensity: intensity,
+		start:     time.Now(),
+		duration:  duration,
+		done:      NewPromise(),
+	}
+	return v.shake.done
+}
+
+func (v *Viewport) cameraMoveTo(pos Positionf, zoom float32, duration time.Duration) Future {
+	v.CameraCancelMovement()
+	v.move = &cameraMove{
+		fromPos:  v.campos,
+		toPos:    pos,
+		fromZoom: v.camzoom,
+		toZoom:   zoom,
+		start:    time.Now(),
+		duration: duration,
+		done:     NewPromise(),
+	}
+	return v.move.done
+}
+
+// clampCamera returns the closest position to the given one where the camera shows no area out of
+// the room with the given zoom.
+func (v *Viewport) clampCamera(pos Positionf, zoom float32) Positionf {
+	if v.Room == nil {
+		return pos
+	}
+	room := v.Room.Rect().Size
+	w, h := cameraVisibleSize(zoom)
+	pos.X = max(0, min(pos.X, float32(room.W)-w))
+	pos.Y = max(0, min(pos.Y, float32(room.H)-h))
+	return pos
+}
+
+// cameraVisibleSize returns the width and height of the area of the room visible in the viewport
+// with the given zoom.
+func cameraVisibleSize(zoom float32) (w, h float32) {
+	return ScreenWidth / zoom, ViewportHeight / zoom
 }
 
 // ProcessFrame processes the frame in the viewport.
 func (v *Viewport) ProcessFrame(f *Frame) {
+	if v.Room != nil {
+		v.Room.renderLightMap()
+	}
+
+	// Rooms taller than the viewport or zoomed in must not be drawn over the control pane.
+	rl.BeginScissorMode(0, 0, int32(ScreenWidth*v.screenZoom), int32(ViewportHeight*v.screenZoom))
+	defer rl.EndScissorMode()
+	defer v.processFrameEffects()
 	if v.Room == nil {
+		v.processFrameCapture()
 		return
 	}
 	f.WithCamera(&v.camera, func(f *Frame) {
 		v.processFrameRoom(f)
+		v.processFrameCapture()
 		v.processFrameDialogs()
-		v.processEvents(f)
+		if !f.DebugEnabled || !v.editor.ProcessFrame(f, v.Room) {
+			// The viewport does not respond to the mouse while covered by a transition.
+			if v.cover == nil {
+				v.processEvents(f)
+			}
+		}
 		v.updateCamera()
 		if f.DebugEnabled && f.MouseIn(v.Room.Rect()) {
 			v.drawMouseCoords(f.MouseRelativePos())
@@ -222,35 +366,57 @@ func (v *Viewport) processEvent(ev ViewportEvent) {
 	}
 }
 
-func (r *Viewport) updateCamera() {
-	if r.Room == nil {
+func (v *Viewport) updateCamera() {
+	if v.Room == nil {
 		return
 	}
-	if r.follow != nil {
-		r.camtarget = int(r.follow.pos.X) - ScreenWidth/2
-	}
-	pos := r.camera.Target().X
-	if pos != r.camtarget {
-		if pos < r.camtarget {
-			pos += RoomCameraSpeed
-			if pos > r.camtarget {
-				pos = r.camtarget
-			}
-		} else {
-			pos -= RoomCameraSpeed
-			if pos < r.camtarget {
-				pos = r.camtarget
-			}
+	now := time.Now()
+	if v.move != nil {
+		pos, zoom, finished := v.move.at(now)
+		v.campos, v.camzoom = v.clampCamera(pos, zoom), zoom
+		v.camtarget = v.campos
+		if finished {
+			v.move.done.Complete()
+			v.move = nil
 		}
-		if pos < 0 {
-			pos = 0
+	} else {
+		if v.follow != nil {
+			v.camtarget = v.clampCamera(v.followTarget(), v.camzoom)
 		}
+		speed := NewPosf(RoomCameraSpeed, RoomCameraSpeed)
+		v.campos = v.clampCamera(v.campos.Move(v.camtarget, speed), v.camzoom)
+	}
 
-		if pos > r.Room.Rect().Size.W-ScreenWidth {
-			pos = r.Room.Rect().Size.W - ScreenWidth
+	target := v.campos
+	if v.shake != nil {
+		offset, finished := v.shake.offset(now)
+		target = target.Add(offset)
+		if finished {
+			v.shake.done.Complete()
+			v.shake = nil
 		}
 	}
-	r.camera = r.camera.WithTarget(NewPos(pos, 0))
+	v.camera = v.camera.WithTarget(target.ToPos()).WithZoom(v.screenZoom * v.camzoom)
+}
+
+// followTarget returns the position the camera must scroll to in order to keep the followed actor
+// within the dead zone around the center of the screen.
+func (v *Viewport) followTarget() Positionf {
+	w, h := cameraVisibleSize(v.camzoom)
+	center := v.camtarget.Add(NewPosf(w/2, h/2))
+	actor := v.follow.pos
+	dx, dy := float32(v.deadzone.W)/2, float32(v.deadzone.H)/2
+	if actor.X < center.X-dx {
+		center.X = actor.X + dx
+	} else if actor.X > center.X+dx {
+		center.X = actor.X - dx
+	}
+	if actor.Y < center.Y-dy {
+		center.Y = actor.Y + dy
+	} else if actor.Y > center.Y+dy {
+		center.Y = actor.Y - dy
+	}
+	return center.Sub(NewPosf(w/2, h/2))
 }
 
 func (m *Viewport) drawMouseCoords(pos Position) {
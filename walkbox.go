@@ -1,8 +1,10 @@
 package pctk
 
 import (
+	"fmt"
 	"log"
 	"math"
+	"slices"
 
 	rl "github.com/gen2brain/raylib-go/raylib"
 )
@@ -11,17 +13,24 @@ import (
 type WalkBox struct {
 	walkBoxID string
 	enabled   bool
-	vertices  [4]Positionf
-	scale     float32
+	vertices  []Positionf
+	scale     float32 // The scale of actors at the bottom edge of the walkbox.
+	farScale  float32 // The scale of actors at the top edge of the walkbox.
+	mask      int     // The index of the room layer actors in the walkbox are drawn behind, or 0 if none.
+	light     Color   // The color actors in the walkbox are tinted with, or blank if none.
+	callbacks []*ScriptCallback
 	room      *Room
 }
 
 // NewWalkBox creates a new WalkBox with the given ID and vertices.
-// It ensures the polygon formed by the vertices is convex. If not, it will cause a panic.
-// Why convex? Because you can draw a straight line/path between any two vertices inside the polygon
-// without needing to implement complex pathfinding algorithms.
-func NewWalkBox(id string, vertices [4]Position, scale float32) *WalkBox {
-	var verticesf [4]Positionf
+// It ensures the polygon formed by the vertices has at least three of them and is convex. If not, it
+// will cause a panic. Why convex? Because you can draw a straight line/path between any two
+// vertices inside the polygon without needing to implement complex pathfinding algorithms.
+func NewWalkBox(id string, vertices []Position, scale float32) *WalkBox {
+	if len(vertices) < 3 {
+		log.Panicf("walkbox must have at least 3 vertices: %v", vertices)
+	}
+	verticesf := make([]Positionf, len(vertices))
 	for i, v := range vertices {
 		verticesf[i] = v.ToPosf()
 	}
@@ -30,6 +39,7 @@ func NewWalkBox(id string, vertices [4]Position, scale float32) *WalkBox {
 		vertices:  verticesf,
 		enabled:   true,
 		scale:     scale,
+		farScale:  scale,
 	}
 
 	if !w.isConvex() {
@@ -38,32 +48,124 @@ func NewWalkBox(id string, vertices [4]Position, scale float32) *WalkBox {
 	return w
 }
 
-// Scale returns the scale factor of the WalkBox for camera zoom effects.
+// DeclareCallback declares a callback in the WalkBox.
+func (w *WalkBox) DeclareCallback(cb *ScriptCallback) error {
+	for _, c := range w.callbacks {
+		if c.Name == cb.Name {
+			return fmt.Errorf("callback '%s' already declared", cb.Name)
+		}
+	}
+	w.callbacks = append(w.callbacks, cb)
+	return nil
+}
+
+// FindCallback finds a callback in the WalkBox by name.
+func (w *WalkBox) FindCallback(name string) *ScriptCallback {
+	for _, c := range w.callbacks {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// Scale returns the scale factor of actors standing at the bottom edge of the WalkBox.
 func (w *WalkBox) Scale() float32 {
 	return w.scale
 }
 
+// FarScale returns the scale factor of actors standing at the top edge of the WalkBox. It is the
+// same as Scale unless the WalkBox has a scale gradient.
+func (w *WalkBox) FarScale() float32 {
+	return w.farScale
+}
+
+// WithScaleGradient sets the scale factor of actors standing at the top edge of the WalkBox. Actors
+// standing between the top and bottom edges are scaled by interpolating both factors along Y.
+func (w *WalkBox) WithScaleGradient(farScale float32) *WalkBox {
+	w.farScale = farScale
+	return w
+}
+
+// Mask returns the index of the room layer, in declaration order starting at 1, the actors standing
+// in the WalkBox are drawn behind, or 0 if the WalkBox has no mask.
+func (w *WalkBox) Mask() int {
+	return w.mask
+}
+
+// WithMask sets the index of the room layer, in declaration order starting at 1, the actors standing
+// in the WalkBox are drawn behind. Actors are drawn behind that layer and the ones in front of it
+// regardless of their baselines.
+func (w *WalkBox) WithMask(mask int) *WalkBox {
+	w.mask = mask
+	return w
+}
+
+// WithLight sets the color the actors standing in the WalkBox are tinted with, on top of the ambient
+// color of the room. A blank color means no tint.
+func (w *WalkBox) WithLight(c Color) *WalkBox {
+	w.light = c
+	return w
+}
+
+// ScaleAt returns the scale factor of actors standing at the given position of the WalkBox.
+func (w *WalkBox) ScaleAt(p Positionf) float32 {
+	top, bottom := w.vertices[0].Y, w.vertices[0].Y
+	for _, v := range w.vertices[1:] {
+		top = min(top, v.Y)
+		bottom = max(bottom, v.Y)
+	}
+	if bottom == top {
+		return w.scale
+	}
+	t := max(0, min(1, (p.Y-top)/(bottom-top)))
+	return w.farScale + (w.scale-w.farScale)*t
+}
+
+// walkBoxColor is the color of the edges of the WalkBoxes in debug mode.
+var walkBoxColor = rl.NewColor(0x55, 0xFF, 0x55, 0x7D)
+
 // Draws the edges of the WalkBox.
 func (w *WalkBox) draw() {
+	w.drawColor(walkBoxColor)
+}
+
+// Draws the edges of the WalkBox with the given color.
+func (w *WalkBox) drawColor(color rl.Color) {
 	numVertices := len(w.vertices)
 	for i := 0; i < numVertices; i++ {
 		p1 := w.vertices[i]
 		p2 := w.vertices[(i+1)%numVertices]
-		rl.DrawLineEx(p1.toRaylib(), p2.toRaylib(), 1.2, rl.NewColor(0x55, 0xFF, 0x55, 0x7D))
+		rl.DrawLineEx(p1.toRaylib(), p2.toRaylib(), 1.2, color)
 	}
 }
 
 // isConvex check if the current WalkBox is a convex poligon.
 func (w *WalkBox) isConvex() bool {
-	numVertices := len(w.vertices)
+	return isConvexPolygon(w.vertices)
+}
+
+// IsConvexPolygon returns true if the given vertices form a convex polygon, as required by
+// walkboxes. Collinear vertices are allowed, but all the vertices cannot be collinear.
+func IsConvexPolygon(vertices []Position) bool {
+	verticesf := make([]Positionf, len(vertices))
+	for i, v := range vertices {
+		verticesf[i] = v.ToPosf()
+	}
+	return isConvexPolygon(verticesf)
+}
+
+func isConvexPolygon(vertices []Positionf) bool {
+	numVertices := len(vertices)
 
 	var totalCrossProduct float32
 	var polygonDirection bool // true if clockwise, false if counter-clockwise
+	var directionSet bool
 	for i := 0; i < numVertices; i++ {
 		// Get three consecutive vertices (cyclically)
-		p1 := w.vertices[i]
-		p2 := w.vertices[(i+1)%numVertices]
-		p3 := w.vertices[(i+2)%numVertices]
+		p1 := vertices[i]
+		p2 := vertices[(i+1)%numVertices]
+		p3 := vertices[(i+2)%numVertices]
 
 		cp := p1.CrossProduct(p2, p3)
 
@@ -73,12 +175,11 @@ func (w *WalkBox) isConvex() bool {
 
 		totalCrossProduct += cp
 
-		if i == 0 {
+		if !directionSet {
 			polygonDirection = cp > 0
-		} else {
-			if (cp > 0) != polygonDirection {
-				return false // If direction changes, the polygon is not convex
-			}
+			directionSet = true
+		} else if (cp > 0) != polygonDirection {
+			return false // If direction changes, the polygon is not convex
 		}
 	}
 	return totalCrossProduct != 0
@@ -86,18 +187,23 @@ func (w *WalkBox) isConvex() bool {
 
 // containsPoint check if the provided position is in the boundaries defined by the WalkBox.
 func (w *WalkBox) containsPoint(p Positionf) bool {
+	return polygonContains(w.vertices, p)
+}
+
+// polygonContains checks if the provided position is in the polygon formed by the given vertices.
+func polygonContains(vertices []Positionf, p Positionf) bool {
 	// Check if the position is one of the vertices
-	for _, vertex := range w.vertices {
+	for _, vertex := range vertices {
 		if p.Equals(vertex) {
 			return true
 		}
 	}
 	numberOfIntersections := 0
-	numVertices := len(w.vertices)
+	numVertices := len(vertices)
 
 	for i := 0; i < numVertices; i++ {
-		p1 := w.vertices[i]
-		p2 := w.vertices[(i+1)%numVertices]
+		p1 := vertices[i]
+		p2 := vertices[(i+1)%numVertices]
 
 		if p.IsIntersecting(p1, p2) {
 			numberOfIntersections++
@@ -107,23 +213,106 @@ func (w *WalkBox) containsPoint(p Positionf) bool {
 	return numberOfIntersections%2 == 1 // Odd count means inside
 }
 
-// isAdjacent checks if two WalkBoxes are adjacent. It returns false if either WalkBox is disabled.
+// isAdjacent checks if two WalkBoxes are adjacent, this is, if they share a segment of any of their
+// edges. Touching in a single point is not enough. It returns false if either WalkBox is disabled.
 func (w *WalkBox) isAdjacent(otherWalkBox *WalkBox) bool {
-	if w.enabled && otherWalkBox.enabled {
-		for _, vertex := range otherWalkBox.vertices {
-			if w.containsPoint(vertex) {
-				return true
+	return w.sharedEdge(otherWalkBox) != nil
+}
+
+// sharedEdge returns the portal between two adjacent WalkBoxes, or nil if they are not adjacent.
+func (w *WalkBox) sharedEdge(otherWalkBox *WalkBox) *portal {
+	if !w.enabled || !otherWalkBox.enabled {
+		return nil
+	}
+	numVertices := len(w.vertices)
+	otherVertices := len(otherWalkBox.vertices)
+	for i := 0; i < numVertices; i++ {
+		p1 := w.vertices[i]
+		p2 := w.vertices[(i+1)%numVertices]
+		for j := 0; j < otherVertices; j++ {
+			q1 := otherWalkBox.vertices[j]
+			q2 := otherWalkBox.vertices[(j+1)%otherVertices]
+			if p := edgesOverlap(p1, p2, q1, q2); p != nil {
+				return p
 			}
 		}
+	}
+	return nil
+}
 
-		// two-way verification
-		for _, vertex := range w.vertices {
-			if otherWalkBox.containsPoint(vertex) {
-				return true
-			}
+// edgeTolerance is the maximum distance between two edges to be considered as shared.
+const edgeTolerance = 0.5
+
+// edgesOverlap returns the segment where p1->p2 and q1->q2 overlap if they lie on the same line and
+// overlap along a segment longer than the edge tolerance, or nil otherwise.
+func edgesOverlap(p1, p2, q1, q2 Positionf) *portal {
+	length := p1.Distance(p2)
+	if length == 0 {
+		return nil
+	}
+	// The distance of q1 and q2 to the line p1->p2.
+	if abs(p1.CrossProduct(p2, q1))/length > edgeTolerance ||
+		abs(p1.CrossProduct(p2, q2))/length > edgeTolerance {
+		return nil
+	}
+	// The projections of q1 and q2 on the segment p1->p2, in units of length.
+	d := p2.Sub(p1)
+	t1 := (q1.Sub(p1).X*d.X + q1.Sub(p1).Y*d.Y) / length
+	t2 := (q2.Sub(p1).X*d.X + q2.Sub(p1).Y*d.Y) / length
+	from := max(0, min(t1, t2))
+	to := min(length, max(t1, t2))
+	if to-from <= edgeTolerance {
+		return nil
+	}
+	return &portal{a: p1.Add(d.Scale(from / length)), b: p1.Add(d.Scale(to / length))}
+}
+
+func abs(v float32) float32 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// centroid returns the average of the vertices of the WalkBox.
+func (w *WalkBox) centroid() Positionf {
+	var c Positionf
+	for _, v := range w.vertices {
+		c = c.Add(v)
+	}
+	return c.Scale(1 / float32(len(w.vertices)))
+}
+
+// exitPoint returns the point where the segment from->to leaves the WalkBox, assuming that from is
+// inside it. It returns to if the segment does not leave the WalkBox.
+func (w *WalkBox) exitPoint(from, to Positionf) Positionf {
+	numVertices := len(w.vertices)
+	var area float32
+	for i := 0; i < numVertices; i++ {
+		p1 := w.vertices[i]
+		p2 := w.vertices[(i+1)%numVertices]
+		area += p1.X*p2.Y - p2.X*p1.Y
+	}
+	orientation := float32(1)
+	if area < 0 {
+		orientation = -1
+	}
+
+	d := to.Sub(from)
+	var exit float32 = 1
+	for i := 0; i < numVertices; i++ {
+		p1 := w.vertices[i]
+		p2 := w.vertices[(i+1)%numVertices]
+		e := p2.Sub(p1)
+		// The outward normal of the edge, and how fast the segment moves along it.
+		n := Positionf{X: e.Y, Y: -e.X}.Scale(orientation)
+		speed := n.X*d.X + n.Y*d.Y
+		if speed > 0 {
+			t := (n.X*(p1.X-from.X) + n.Y*(p1.Y-from.Y)) / speed
+			exit = min(exit, max(0, t))
 		}
 	}
-	return false
+	return from.Add(d.Scale(exit))
 }
 
 // distance calculates the shortest distance from the WalkBox to the given position.
@@ -147,13 +336,27 @@ func (wb *WalkBox) distance(p Positionf) float32 {
 
 // WalkBoxMatrix represents a collection of WalkBoxes and their adjacency relationships.
 type WalkBoxMatrix struct {
-	walkBoxes       []*WalkBox
-	itineraryMatrix [][]int
+	walkBoxes []*WalkBox
+	portals   [][]*portal
+}
+
+// portal is the segment shared by two adjacent WalkBoxes, through which actors walk from one to the
+// other.
+type portal struct {
+	a, b Positionf
+}
+
+// midpoint returns the middle point of the portal.
+func (p *portal) midpoint() Positionf {
+	return p.a.Add(p.b).Scale(0.5)
+}
+
+// contains returns true if the given position lies on the portal.
+func (p *portal) contains(pos Positionf) bool {
+	return pos.Distance(pos.ClosestPointOnSegment(p.a, p.b)) <= edgeTolerance
 }
 
 const (
-	// infinityDistance represents a maximum distance value used for unconnected paths.
-	infinityDistance = 255
 	// InvalidWalkBox indicates an invalid WalkBox ID, typically used to signify non-existence.
 	InvalidWalkBox = -1
 )
@@ -164,7 +367,7 @@ func NewWalkBoxMatrix(walkboxes []*WalkBox) *WalkBoxMatrix {
 		walkBoxes: walkboxes,
 	}
 
-	wm.resetItinerary()
+	wm.resetPortals()
 	return wm
 }
 
@@ -177,56 +380,26 @@ func (wm *WalkBoxMatrix) Draw() {
 	}
 }
 
-// resetItinerary computes the shortest paths between WalkBoxes and returns the resulting itinerary matrix.
-func (wm *WalkBoxMatrix) resetItinerary() {
+// resetPortals computes the portals between each pair of adjacent WalkBoxes.
+func (wm *WalkBoxMatrix) resetPortals() {
 	numBoxes := len(wm.walkBoxes)
-	distanceMatrix := make([][]int, numBoxes)
-	itineraryMatrix := make([][]int, numBoxes)
-
+	wm.portals = make([][]*portal, numBoxes)
 	for i, walkbox := range wm.walkBoxes {
-		itineraryMatrix[i] = make([]int, numBoxes)
-		distanceMatrix[i] = make([]int, numBoxes)
-
-		// Initialize the distance matrix: each box has distance 0 to itself,
-		// and distance 1 to its direct neighbors. Initially, it has distance
-		// 255 (= infinityDistance) to all other boxes.
+		wm.portals[i] = make([]*portal, numBoxes)
 		for j, otherWalkBox := range wm.walkBoxes {
-			if i == j {
-				distanceMatrix[i][j] = 0
-				itineraryMatrix[i][j] = i
-			} else if walkbox.isAdjacent(otherWalkBox) {
-				distanceMatrix[i][j] = 1
-				itineraryMatrix[i][j] = j
-			} else {
-				distanceMatrix[i][j] = infinityDistance
-				itineraryMatrix[i][j] = InvalidWalkBox
-			}
-		}
-	}
-
-	// Compute the shortest routes between boxes via Kleene's algorithm.
-	for i := range wm.walkBoxes {
-		for j := range wm.walkBoxes {
-			for k := range wm.walkBoxes {
-				distIK := distanceMatrix[i][k]
-				distKJ := distanceMatrix[k][j]
-				if distanceMatrix[i][j] > distIK+distKJ {
-					distanceMatrix[i][j] = distIK + distKJ
-					itineraryMatrix[i][j] = k
-				}
+			if i != j {
+				wm.portals[i][j] = walkbox.sharedEdge(otherWalkBox)
 			}
 		}
 	}
-
-	wm.itineraryMatrix = itineraryMatrix
 }
 
-// EnableWalkBox enables or disables the specified walk box and recalculates the itinerary matrix.
+// EnableWalkBox enables or disables the specified walk box and recalculates the portals.
 func (wm *WalkBoxMatrix) EnableWalkBox(id string, enabled bool) {
 	for _, w := range wm.walkBoxes {
 		if w.walkBoxID == id {
 			w.enabled = enabled
-			wm.resetItinerary()
+			wm.resetPortals()
 			break
 		}
 	}
@@ -240,42 +413,188 @@ type WayPoint struct {
 
 // FindPath calculates and returns a path as a sequence of waypoints from the
 // starting point 'from' to the destination 'to' within the walk box matrix.
-// The path is returned as a slice of waypoints.
+// The path is returned as a slice of waypoints, starting at 'from'. If 'to' is not walkable, the
+// path ends at the closest position of the closest walk box reachable from 'from'.
+//
+// Actors walk straight to the destination if nothing but walkable space lies in between. Otherwise,
+// the sequence of walk boxes with the shortest distance through the portals between them is found,
+// and the path is the shortest one along that sequence, turning only at the corners of the portals.
+// With no walk boxes, nothing limits the walkable space and the path goes straight to 'to'.
 func (wm *WalkBoxMatrix) FindPath(from, to Position) []*WayPoint {
-	var path []*WayPoint
+	if wm == nil || len(wm.walkBoxes) == 0 {
+		return []*WayPoint{{Position: from}, {Position: to}}
+	}
 	fromf := from.ToPosf()
 	tof := to.ToPosf()
-	current, _ := wm.walkBoxAt(fromf)
+	start, _ := wm.walkBoxAt(fromf)
 	target, _ := wm.walkBoxAt(tof)
 
-	path = append(path, &WayPoint{Walkbox: wm.walkBoxes[current], Position: from})
-	for current != target {
-		next := wm.nextWalkBox(current, target)
-		if next == InvalidWalkBox {
+	boxes := wm.findCorridor(start, target, fromf, tof)
+	last := boxes[len(boxes)-1]
+	end := wm.closestPositionOnWalkBox(last, tof)
+
+	path := []*WayPoint{{Walkbox: wm.walkBoxes[start], Position: from}}
+	if wm.isWalkable(start, fromf, end) {
+		return append(path, &WayPoint{Walkbox: wm.walkBoxes[last], Position: end.ToPos()})
+	}
+	return append(path, wm.pullString(boxes, fromf, end)...)
+}
+
+// findCorridor returns the sequence of walk boxes from start to target with the shortest distance,
+// using the A* algorithm over the portals between them. Each walk box is entered through the middle
+// point of its portal. If target is not reachable, the sequence ends at the reachable walk box
+// closest to the destination.
+func (wm *WalkBoxMatrix) findCorridor(start, target int, from, to Positionf) []int {
+	numBoxes := len(wm.walkBoxes)
+	cost := make([]float32, numBoxes)
+	entry := make([]Positionf, numBoxes)
+	prev := make([]int, numBoxes)
+	closed := make([]bool, numBoxes)
+	for i := range cost {
+		cost[i] = math.MaxFloat32
+		prev[i] = InvalidWalkBox
+	}
+	cost[start] = 0
+	entry[start] = from
+
+	estimate := func(box int) float32 {
+		return cost[box] + entry[box].Distance(to)
+	}
+	closest := start
+	open := []int{start}
+	for len(open) > 0 {
+		next := 0
+		for i := range open {
+			if estimate(open[i]) < estimate(open[next]) {
+				next = i
+			}
+		}
+		current := open[next]
+		open = slices.Delete(open, next, next+1)
+		if closed[current] {
+			continue
+		}
+		closed[current] = true
+		if current == target {
+			closest = target
 			break
 		}
-		nextPosition := wm.closestPositionToWalkBox(fromf, next)
-		path = append(path, &WayPoint{Walkbox: wm.walkBoxes[next], Position: nextPosition.ToPos()})
-		current = next
-		fromf = nextPosition
+		if wm.walkBoxes[current].distance(to) < wm.walkBoxes[closest].distance(to) {
+			closest = current
+		}
+
+		for neighbor, p := range wm.portals[current] {
+			if p == nil || closed[neighbor] {
+				continue
+			}
+			mid := p.midpoint()
+			if c := cost[current] + entry[current].Distance(mid); c < cost[neighbor] {
+				cost[neighbor] = c
+				entry[neighbor] = mid
+				prev[neighbor] = current
+				open = append(open, neighbor)
+			}
+		}
 	}
 
-	path = append(path, &WayPoint{Walkbox: wm.walkBoxes[current], Position: wm.closestPositionOnWalkBox(current, tof).ToPos()})
-	return path
+	var boxes []int
+	for box := closest; box != InvalidWalkBox; box = prev[box] {
+		boxes = append(boxes, box)
+	}
+	slices.Reverse(boxes)
+	return boxes
 }
 
-// nextWalkBox returns the next walk box in the path from the source to the destination.
-func (wm *WalkBoxMatrix) nextWalkBox(from, to int) int {
-	if from < 0 || from >= len(wm.walkBoxes) || to < 0 || to >= len(wm.walkBoxes) {
-		return InvalidWalkBox
+// pullString returns the waypoints of the shortest path from one position to another through the
+// portals between the given sequence of walk boxes, using the simple stupid funnel algorithm. The
+// starting position is not included.
+func (wm *WalkBoxMatrix) pullString(boxes []int, from, to Positionf) []*WayPoint {
+	// The left and right sides of each portal as seen when walking through the corridor. The first
+	// and last portals are the starting and ending positions.
+	lefts := []Positionf{from}
+	rights := []Positionf{from}
+	for i := 0; i+1 < len(boxes); i++ {
+		p := wm.portals[boxes[i]][boxes[i+1]]
+		c := wm.walkBoxes[boxes[i]].centroid()
+		if c.CrossProduct(p.a, p.b) > 0 {
+			lefts, rights = append(lefts, p.b), append(rights, p.a)
+		} else {
+			lefts, rights = append(lefts, p.a), append(rights, p.b)
+		}
+	}
+	lefts, rights = append(lefts, to), append(rights, to)
+
+	var path []*WayPoint
+	corner := func(pos Positionf, index int) {
+		if !pos.Equals(from) && (len(path) == 0 || path[len(path)-1].Position != pos.ToPos()) {
+			path = append(path, &WayPoint{Walkbox: wm.walkBoxes[boxes[index]], Position: pos.ToPos()})
+		}
 	}
 
-	if wm.itineraryMatrix[from][to] == to {
-		return to
+	apex, left, right := from, from, from
+	apexIndex, leftIndex, rightIndex := 0, 0, 0
+	for i := 1; i < len(lefts); i++ {
+		l, r := lefts[i], rights[i]
+		if triangleArea(apex, right, r) <= 0 {
+			if apex.Equals(right) || triangleArea(apex, left, r) > 0 {
+				right, rightIndex = r, i
+			} else {
+				// The right side crosses over the left one, so the left one is a corner.
+				corner(left, min(leftIndex, len(boxes)-1))
+				apex, apexIndex = left, leftIndex
+				left, right = apex, apex
+				leftIndex, rightIndex = apexIndex, apexIndex
+				i = apexIndex
+				continue
+			}
+		}
+		if triangleArea(apex, left, l) >= 0 {
+			if apex.Equals(left) || triangleArea(apex, right, l) < 0 {
+				left, leftIndex = l, i
+			} else {
+				// The left side crosses over the right one, so the right one is a corner.
+				corner(right, min(rightIndex, len(boxes)-1))
+				apex, apexIndex = right, rightIndex
+				left, right = apex, apex
+				leftIndex, rightIndex = apexIndex, apexIndex
+				i = apexIndex
+				continue
+			}
+		}
 	}
+	corner(to, len(boxes)-1)
+	return path
+}
+
+// triangleArea returns twice the signed area of the triangle formed by the given positions. Its
+// sign tells the side of the segment a->b where c lies.
+func triangleArea(a, b, c Positionf) float32 {
+	return (c.X-a.X)*(b.Y-a.Y) - (b.X-a.X)*(c.Y-a.Y)
+}
 
-	next := wm.itineraryMatrix[from][to]
-	return wm.nextWalkBox(from, next)
+// isWalkable returns true if the straight segment between two positions lies in walkable space,
+// starting at the given walk box.
+func (wm *WalkBoxMatrix) isWalkable(box int, from, to Positionf) bool {
+	prev := InvalidWalkBox
+	for range wm.walkBoxes {
+		wb := wm.walkBoxes[box]
+		if wb.containsPoint(to) || wb.distance(to) <= edgeTolerance {
+			return true
+		}
+		exit := wb.exitPoint(from, to)
+		next := InvalidWalkBox
+		for neighbor, p := range wm.portals[box] {
+			if p != nil && neighbor != prev && p.contains(exit) {
+				next = neighbor
+				break
+			}
+		}
+		if next == InvalidWalkBox {
+			return false
+		}
+		prev, box = box, next
+	}
+	return false
 }
 
 // walkBoxAt returns the walk box identifier at the given position or the closest one,
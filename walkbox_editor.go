@@ -0,0 +1,358 @@
+package pctk
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+const (
+	// walkBoxEditorVertexRadius is the distance to a vertex where the cursor grabs it.
+	walkBoxEditorVertexRadius = 3
+	// walkBoxEditorScaleStep is the amount the scale changes with each key press.
+	walkBoxEditorScaleStep = 0.05
+)
+
+var (
+	walkBoxEditorSelected = rl.NewColor(0xFF, 0xFF, 0x55, 0xFF)
+	walkBoxEditorDisabled = rl.NewColor(0xAA, 0xAA, 0xAA, 0x7D)
+	walkBoxEditorInvalid  = rl.NewColor(0xFF, 0x55, 0x55, 0xFF)
+	walkBoxEditorPortal   = rl.NewColor(0x55, 0xFF, 0xFF, 0xFF)
+	walkBoxEditorPath     = rl.NewColor(0xFF, 0xAA, 0x00, 0xFF)
+)
+
+// walkBoxEditor is an interactive editor of the walkboxes of the room, available in debug mode. It
+// is toggled with the E key and, while active, it takes over the mouse input of the viewport:
+//   - Left click selects a walkbox, and dragging moves the vertex under the cursor.
+//   - N adds a new walkbox at the cursor, and Delete removes the selected one.
+//   - V adds a vertex on the closest edge of the selected walkbox, and X removes the one under the
+//     cursor.
+//   - T toggles whether the selected walkbox is enabled.
+//   - + and - change the scale of the selected walkbox, or its far scale while holding Shift.
+//   - Right clicks set the start and the end of a test path.
+//   - L and M copy the walkboxes to the clipboard as a Lua snippet or as a room manifest.
+type walkBoxEditor struct {
+	active   bool
+	room     *Room
+	selected *WalkBox
+	dragging bool
+	vertex   int
+	from, to *Position
+	path     []*WayPoint
+}
+
+// ProcessFrame processes the frame in the editor. It returns true if the editor is active, in which
+// case the mouse input must not be processed by the viewport.
+func (e *walkBoxEditor) ProcessFrame(f *Frame, room *Room) bool {
+	if rl.IsKeyPressed(rl.KeyE) {
+		e.active = !e.active
+	}
+	if !e.active {
+		return false
+	}
+	if room != e.room {
+		*e = walkBoxEditor{active: true, room: room}
+	}
+
+	if f.MouseIn(room.Rect()) {
+		mpos := f.MouseRelativePos()
+		e.processMouse(f, mpos)
+		e.processKeys(mpos)
+	}
+	e.draw(f)
+	return true
+}
+
+func (e *walkBoxEditor) walkBoxes() []*WalkBox {
+	if e.room.wbmatrix == nil {
+		return nil
+	}
+	return e.room.wbmatrix.walkBoxes
+}
+
+func (e *walkBoxEditor) processMouse(f *Frame, mpos Position) {
+	if f.Mouse.LeftClick() {
+		e.selected, e.vertex = e.vertexAt(mpos)
+		e.dragging = e.selected != nil
+		if !e.dragging {
+			e.selected = e.walkBoxAt(mpos)
+		}
+	}
+	if e.dragging {
+		if !f.Mouse.LeftDown() {
+			e.dragging = false
+		} else if v := mpos.ToPosf(); !e.selected.vertices[e.vertex].Equals(v) {
+			e.selected.vertices[e.vertex] = v
+			e.changed()
+		}
+	}
+	if f.Mouse.RightClick() {
+		pos := mpos
+		if e.from == nil || e.to != nil {
+			e.from, e.to, e.path = &pos, nil, nil
+		} else {
+			e.to = &pos
+			e.changed()
+		}
+	}
+}
+
+func (e *walkBoxEditor) processKeys(mpos Position) {
+	if rl.IsKeyPressed(rl.KeyN) {
+		e.add(mpos)
+	}
+	if rl.IsKeyPressed(rl.KeyL) {
+		e.export("Lua snippet", walkBoxesLua(e.walkBoxes()))
+	}
+	if rl.IsKeyPressed(rl.KeyM) {
+		e.export("room manifest", walkBoxesManifest(e.walkBoxes()))
+	}
+
+	wb := e.selected
+	if wb == nil {
+		return
+	}
+	switch {
+	case rl.IsKeyPressed(rl.KeyDelete) || rl.IsKeyPressed(rl.KeyBackspace):
+		e.remove(wb)
+	case rl.IsKeyPressed(rl.KeyV):
+		wb.insertVertex(mpos.ToPosf())
+		e.changed()
+	case rl.IsKeyPressed(rl.KeyX):
+		if sel, v := e.vertexAt(mpos); sel == wb && len(wb.vertices) > 3 {
+			wb.vertices = append(wb.vertices[:v], wb.vertices[v+1:]...)
+			e.changed()
+		}
+	case rl.IsKeyPressed(rl.KeyT):
+		wb.enabled = !wb.enabled
+		e.changed()
+	case rl.IsKeyPressed(rl.KeyEqual) || rl.IsKeyPressed(rl.KeyKpAdd):
+		e.rescale(wb, walkBoxEditorScaleStep)
+	case rl.IsKeyPressed(rl.KeyMinus) || rl.IsKeyPressed(rl.KeyKpSubtract):
+		e.rescale(wb, -walkBoxEditorScaleStep)
+	}
+}
+
+// vertexAt returns the walkbox and the index of its vertex under the given position. Vertices of
+// the selected walkbox take precedence over the others.
+func (e *walkBoxEditor) vertexAt(pos Position) (*WalkBox, int) {
+	candidates := e.walkBoxes()
+	if e.selected != nil {
+		candidates = append([]*WalkBox{e.selected}, candidates...)
+	}
+	posf := pos.ToPosf()
+	for _, wb := range candidates {
+		for i, v := range wb.vertices {
+			if v.Distance(posf) <= walkBoxEditorVertexRadius {
+				return wb, i
+			}
+		}
+	}
+	return nil, 0
+}
+
+// walkBoxAt returns the walkbox that contains the given position, or nil if there is none.
+func (e *walkBoxEditor) walkBoxAt(pos Position) *WalkBox {
+	for _, wb := range e.walkBoxes() {
+		if wb.containsPoint(pos.ToPosf()) {
+			return wb
+		}
+	}
+	return nil
+}
+
+// add adds a new square walkbox centered at the given position.
+func (e *walkBoxEditor) add(pos Position) {
+	if e.room.wbmatrix == nil {
+		e.room.wbmatrix = NewWalkBoxMatrix(nil)
+	}
+	ids := make(map[string]bool)
+	for _, wb := range e.walkBoxes() {
+		ids[wb.walkBoxID] = true
+	}
+	id := "walkbox0"
+	for i := 1; ids[id]; i++ {
+		id = fmt.Sprintf("walkbox%d", i)
+	}
+
+	const half = 10
+	wb := NewWalkBox(id, []Position{
+		{pos.X - half, pos.Y - half},
+		{pos.X + half, pos.Y - half},
+		{pos.X + half, pos.Y + half},
+		{pos.X - half, pos.Y + half},
+	}, 1)
+	wb.room = e.room
+	e.room.wbmatrix.walkBoxes = append(e.room.wbmatrix.walkBoxes, wb)
+	e.selected = wb
+	e.changed()
+}
+
+// remove removes the given walkbox from the room.
+func (e *walkBoxEditor) remove(wb *WalkBox) {
+	var walkboxes []*WalkBox
+	for _, w := range e.walkBoxes() {
+		if w != wb {
+			walkboxes = append(walkboxes, w)
+		}
+	}
+	e.selected = nil
+	e.room.wbmatrix.walkBoxes = walkboxes
+	e.changed()
+}
+
+// rescale changes the scale of the walkbox, or its far scale if Shift is held down. Walkboxes with
+// no scale gradient keep having none when their scale changes.
+func (e *walkBoxEditor) rescale(wb *WalkBox, delta float32) {
+	step := func(s float32) float32 {
+		return max(walkBoxEditorScaleStep, float32(math.Round(float64(s+delta)*100)/100))
+	}
+	if rl.IsKeyDown(rl.KeyLeftShift) || rl.IsKeyDown(rl.KeyRightShift) {
+		wb.farScale = step(wb.farScale)
+		return
+	}
+	if wb.farScale == wb.scale {
+		wb.farScale = step(wb.farScale)
+	}
+	wb.scale = step(wb.scale)
+}
+
+// changed recomputes the portals between walkboxes and the test path after an edition.
+func (e *walkBoxEditor) changed() {
+	if e.room.wbmatrix == nil {
+		return
+	}
+	e.room.wbmatrix.resetPortals()
+	if e.from != nil && e.to != nil {
+		e.path = e.room.wbmatrix.FindPath(*e.from, *e.to)
+	}
+}
+
+func (e *walkBoxEditor) export(format, text string) {
+	rl.SetClipboardText(text)
+	log.Printf("Walkboxes copied to clipboard as %s:\n%s", format, text)
+}
+
+func (e *walkBoxEditor) draw(f *Frame) {
+	walkboxes := e.walkBoxes()
+	for _, wb := range walkboxes {
+		color := walkBoxColor
+		switch {
+		case !wb.isConvex():
+			color = walkBoxEditorInvalid
+		case wb == e.selected:
+			color = walkBoxEditorSelected
+		case !wb.enabled:
+			color = walkBoxEditorDisabled
+		}
+		wb.drawColor(color)
+		for _, v := range wb.vertices {
+			rl.DrawCircleV(v.toRaylib(), 1.5, color)
+		}
+	}
+
+	if wm := e.room.wbmatrix; wm != nil {
+		for i := range wm.walkBoxes {
+			for j := i + 1; j < len(wm.walkBoxes); j++ {
+				if p := wm.portals[i][j]; p != nil {
+					rl.DrawLineEx(p.a.toRaylib(), p.b.toRaylib(), 2, walkBoxEditorPortal)
+					rl.DrawLineEx(
+						wm.walkBoxes[i].centroid().toRaylib(),
+						wm.walkBoxes[j].centroid().toRaylib(),
+						1, walkBoxEditorPortal)
+				}
+			}
+		}
+	}
+
+	if e.from != nil {
+		rl.DrawCircleV(e.from.toRaylib(), 2, walkBoxEditorPath)
+	}
+	for i := 0; i+1 < len(e.path); i++ {
+		rl.DrawLineEx(e.path[i].Position.toRaylib(), e.path[i+1].Position.toRaylib(), 1, walkBoxEditorPath)
+	}
+
+	origin := f.Camera.Target()
+	status := "WALKBOX EDITOR: N new, L/M export"
+	if wb := e.selected; wb != nil {
+		status = fmt.Sprintf("%s: scale %.2f, far %.2f, enabled %t", wb.walkBoxID, wb.scale, wb.farScale, wb.enabled)
+	}
+	rl.DrawText(status, int32(origin.X+2), int32(origin.Y+2), 10, White)
+}
+
+// insertVertex inserts a new vertex in the edge of the WalkBox that is closest to the given
+// position, at the point of the edge that is closest to it.
+func (w *WalkBox) insertVertex(p Positionf) {
+	numVertices := len(w.vertices)
+	var minDistance float32 = -1
+	var edge int
+	var vertex Positionf
+	for i := 0; i < numVertices; i++ {
+		closest := p.ClosestPointOnSegment(w.vertices[i], w.vertices[(i+1)%numVertices])
+		if d := p.Distance(closest); minDistance < 0 || d < minDistance {
+			minDistance, edge, vertex = d, i, closest
+		}
+	}
+	w.vertices = append(w.vertices[:edge+1], append([]Positionf{vertex}, w.vertices[edge+1:]...)...)
+}
+
+// walkBoxesLua returns the given walkboxes as the walkboxes field of a Lua room constructor.
+func walkBoxesLua(walkboxes []*WalkBox) string {
+	var b strings.Builder
+	b.WriteString("walkboxes = {\n")
+	for _, wb := range walkboxes {
+		fmt.Fprintf(&b, "    %s = walkbox {\n", wb.walkBoxID)
+		b.WriteString("        vertices = {\n")
+		for _, v := range wb.vertices {
+			p := v.ToPos()
+			fmt.Fprintf(&b, "            pos {x=%d, y=%d},\n", p.X, p.Y)
+		}
+		b.WriteString("        },\n")
+		fmt.Fprintf(&b, "        scale = %s,\n", formatScale(wb.scale))
+		if wb.farScale != wb.scale {
+			fmt.Fprintf(&b, "        farscale = %s,\n", formatScale(wb.farScale))
+		}
+		if wb.mask != 0 {
+			fmt.Fprintf(&b, "        mask = %d,\n", wb.mask)
+		}
+		if !wb.enabled {
+			b.WriteString("        enabled = false,\n")
+		}
+		b.WriteString("    },\n")
+	}
+	b.WriteString("},\n")
+	return b.String()
+}
+
+// walkBoxesManifest returns the given walkboxes as the walkboxes field of a room manifest.
+func walkBoxesManifest(walkboxes []*WalkBox) string {
+	var b strings.Builder
+	b.WriteString("walkboxes:\n")
+	for _, wb := range walkboxes {
+		fmt.Fprintf(&b, "  - id: %s\n", wb.walkBoxID)
+		b.WriteString("    vertices:\n")
+		for _, v := range wb.vertices {
+			p := v.ToPos()
+			fmt.Fprintf(&b, "      - {x: %d, y: %d}\n", p.X, p.Y)
+		}
+		fmt.Fprintf(&b, "    scale: %s\n", formatScale(wb.scale))
+		if wb.farScale != wb.scale {
+			fmt.Fprintf(&b, "    farscale: %s\n", formatScale(wb.farScale))
+		}
+		if wb.mask != 0 {
+			fmt.Fprintf(&b, "    mask: %d\n", wb.mask)
+		}
+		if !wb.enabled {
+			b.WriteString("    disabled: true\n")
+		}
+	}
+	return b.String()
+}
+
+func formatScale(s float32) string {
+	return strconv.FormatFloat(float64(s), 'f', -1, 32)
+}
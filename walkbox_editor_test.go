@@ -0,0 +1,78 @@
+package pctk
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testEditorWalkBoxes() []*WalkBox {
+	floor := NewWalkBox("floor", []Position{{0, 100}, {320, 100}, {320, 140}, {0, 140}}, 1)
+	floor.WithScaleGradient(0.8)
+	stairs := NewWalkBox("stairs", []Position{{100, 80}, {120, 100}, {80, 100}}, 0.75)
+	stairs.enabled = false
+	return []*WalkBox{floor, stairs}
+}
+
+func TestWalkBoxesLua(t *testing.T) {
+	assert.Equal(t, `walkboxes = {
+    floor = walkbox {
+        vertices = {
+            pos {x=0, y=100},
+            pos {x=320, y=100},
+            pos {x=320, y=140},
+            pos {x=0, y=140},
+        },
+        scale = 1,
+        farscale = 0.8,
+    },
+    stairs = walkbox {
+        vertices = {
+            pos {x=100, y=80},
+            pos {x=120, y=100},
+            pos {x=80, y=100},
+        },
+        scale = 0.75,
+        enabled = false,
+    },
+},
+`, walkBoxesLua(testEditorWalkBoxes()))
+}
+
+func TestWalkBoxesManifest(t *testing.T) {
+	assert.Equal(t, `walkboxes:
+  - id: floor
+    vertices:
+      - {x: 0, y: 100}
+      - {x: 320, y: 100}
+      - {x: 320, y: 140}
+      - {x: 0, y: 140}
+    scale: 1
+    farscale: 0.8
+  - id: stairs
+    vertices:
+      - {x: 100, y: 80}
+      - {x: 120, y: 100}
+      - {x: 80, y: 100}
+    scale: 0.75
+    disabled: true
+`, walkBoxesManifest(testEditorWalkBoxes()))
+}
+
+func TestWalkBox_InsertVertex(t *testing.T) {
+	wb := NewWalkBox("floor", []Position{{0, 0}, {10, 0}, {10, 10}, {0, 10}}, 1)
+	wb.insertVertex(NewPosf(12, 5))
+	assert.Equal(t, []Positionf{{0, 0}, {10, 0}, {10, 5}, {10, 10}, {0, 10}}, wb.vertices)
+}
+
+func TestWalkBoxEditor_RemoveLast(t *testing.T) {
+	room := NewRoom()
+	room.DeclareWalkBoxMatrix(testEditorWalkBoxes()[:1])
+	from, to := NewPos(10, 120), NewPos(300, 120)
+	e := &walkBoxEditor{room: room, from: &from, to: &to}
+
+	e.remove(room.wbmatrix.walkBoxes[0])
+	assert.Empty(t, e.walkBoxes())
+	assert.Equal(t, []*WayPoint{{Position: from}, {Position: to}}, e.path)
+	assert.Len(t, room.wbmatrix.FindPath(from, to), 2)
+}
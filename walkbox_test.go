@@ -15,25 +15,25 @@ const (
 func TestNewWalkBox(t *testing.T) {
 	testCases := []struct {
 		name        string
-		vertices    [4]pctk.Position
+		vertices    []pctk.Position
 		shouldPanic bool
 		message     string
 	}{
 		{
 			name:        "Concave polygon should panic",
-			vertices:    [4]pctk.Position{{X: 0, Y: 0}, {X: 4, Y: 0}, {X: 2, Y: 1}, {X: 4, Y: 4}},
+			vertices:    []pctk.Position{{X: 0, Y: 0}, {X: 4, Y: 0}, {X: 2, Y: 1}, {X: 4, Y: 4}},
 			shouldPanic: true,
 			message:     "Expected panic because vertices form a concave polygon!",
 		},
 		{
 			name:        "Collinear vertices should panic",
-			vertices:    [4]pctk.Position{{X: 1, Y: 2}, {X: 2, Y: 4}, {X: 3, Y: 6}, {X: 4, Y: 8}},
+			vertices:    []pctk.Position{{X: 1, Y: 2}, {X: 2, Y: 4}, {X: 3, Y: 6}, {X: 4, Y: 8}},
 			shouldPanic: true,
 			message:     "Expected panic because vertices are collinear!",
 		},
 		{
 			name:        "Should successfully create a valid WalkBox with a convex polygon",
-			vertices:    [4]pctk.Position{{X: 0, Y: 0}, {X: 4, Y: 0}, {X: 4, Y: 4}, {X: 0, Y: 4}},
+			vertices:    []pctk.Position{{X: 0, Y: 0}, {X: 4, Y: 0}, {X: 4, Y: 4}, {X: 0, Y: 4}},
 			shouldPanic: false,
 			message:     "Expected create a valid WalkBox, vertices form a convex polygon!",
 		},
@@ -41,6 +41,7 @@ func TestNewWalkBox(t *testing.T) {
 
 	for _, testCase := range testCases {
 		t.Run(testCase.name, func(t *testing.T) {
+			assert.Equal(t, !testCase.shouldPanic, pctk.IsConvexPolygon(testCase.vertices))
 			if testCase.shouldPanic {
 				assert.Panics(t, func() {
 					pctk.NewWalkBox(DefaultWalkBoxID, testCase.vertices, DefaultScale)
@@ -79,9 +80,9 @@ func TestFindPath(t *testing.T) {
 
 		Each box represents a square, except box7, which is three times taller.
 		- box0 is adjacent to box1, box3
-		- box1 is adjacent to box0, box2, box3, box4
+		- box1 is adjacent to box0, box2, box4
 		- box2 is adjacent to box1, box5
-		- box3 is adjacent to box0, box1, box4
+		- box3 is adjacent to box0, box4
 		- box4 is adjacent to box1, box3, box5, box7
 		- box5 is adjacent to box2, box4
 		- box6 is adjacent to box7 (positioned above box3 but not connected)
@@ -116,15 +117,15 @@ func TestFindPath(t *testing.T) {
 		},
 	}
 
-	box0 := pctk.NewWalkBox("walkbox0", [4]pctk.Position{{0, 0}, {1, 0}, {1, 1}, {0, 1}}, DefaultScale)
-	box1 := pctk.NewWalkBox("walkbox1", [4]pctk.Position{{1, 0}, {2, 0}, {2, 1}, {1, 1}}, DefaultScale)
-	box2 := pctk.NewWalkBox("walkbox2", [4]pctk.Position{{2, 0}, {3, 0}, {3, 1}, {2, 1}}, DefaultScale)
-	box3 := pctk.NewWalkBox("walkbox3", [4]pctk.Position{{0, 1}, {1, 1}, {1, 2}, {0, 2}}, DefaultScale)
-	box4 := pctk.NewWalkBox("walkbox4", [4]pctk.Position{{1, 1}, {2, 1}, {2, 2}, {1, 2}}, DefaultScale)
-	box5 := pctk.NewWalkBox("walkbox5", [4]pctk.Position{{2, 1}, {3, 1}, {3, 2}, {2, 2}}, DefaultScale)
-	box6 := pctk.NewWalkBox("walkbox6", [4]pctk.Position{{1, 4}, {0, 4}, {0, 3}, {1, 3}}, DefaultScale) // starts in top right vertex on purpose
-	box7 := pctk.NewWalkBox("walkbox7", [4]pctk.Position{{1, 2}, {2, 2}, {2, 5}, {1, 5}}, DefaultScale)
-	box8 := pctk.NewWalkBox("walkbox8", [4]pctk.Position{{2, 3}, {3, 3}, {3, 4}, {2, 4}}, DefaultScale)
+	box0 := pctk.NewWalkBox("walkbox0", []pctk.Position{{0, 0}, {1, 0}, {1, 1}, {0, 1}}, DefaultScale)
+	box1 := pctk.NewWalkBox("walkbox1", []pctk.Position{{1, 0}, {2, 0}, {2, 1}, {1, 1}}, DefaultScale)
+	box2 := pctk.NewWalkBox("walkbox2", []pctk.Position{{2, 0}, {3, 0}, {3, 1}, {2, 1}}, DefaultScale)
+	box3 := pctk.NewWalkBox("walkbox3", []pctk.Position{{0, 1}, {1, 1}, {1, 2}, {0, 2}}, DefaultScale)
+	box4 := pctk.NewWalkBox("walkbox4", []pctk.Position{{1, 1}, {2, 1}, {2, 2}, {1, 2}}, DefaultScale)
+	box5 := pctk.NewWalkBox("walkbox5", []pctk.Position{{2, 1}, {3, 1}, {3, 2}, {2, 2}}, DefaultScale)
+	box6 := pctk.NewWalkBox("walkbox6", []pctk.Position{{1, 4}, {0, 4}, {0, 3}, {1, 3}}, DefaultScale) // starts in top right vertex on purpose
+	box7 := pctk.NewWalkBox("walkbox7", []pctk.Position{{1, 2}, {2, 2}, {2, 5}, {1, 5}}, DefaultScale)
+	box8 := pctk.NewWalkBox("walkbox8", []pctk.Position{{2, 3}, {3, 3}, {3, 4}, {2, 4}}, DefaultScale)
 
 	walkBoxMatrix := pctk.NewWalkBoxMatrix([]*pctk.WalkBox{box0, box1, box2, box3, box4, box5, box6, box7, box8})
 
@@ -135,3 +136,76 @@ func TestFindPath(t *testing.T) {
 		})
 	}
 }
+
+func TestWalkBox_ScaleAt(t *testing.T) {
+	vertices := []pctk.Position{{X: 0, Y: 100}, {X: 100, Y: 100}, {X: 100, Y: 140}, {X: 0, Y: 140}}
+
+	wb := pctk.NewWalkBox(DefaultWalkBoxID, vertices, 0.8)
+	assert.Equal(t, float32(0.8), wb.ScaleAt(pctk.NewPos(50, 100).ToPosf()))
+	assert.Equal(t, float32(0.8), wb.ScaleAt(pctk.NewPos(50, 140).ToPosf()))
+
+	wb = pctk.NewWalkBox(DefaultWalkBoxID, vertices, 1).WithScaleGradient(0.6)
+	assert.Equal(t, float32(0.6), wb.ScaleAt(pctk.NewPos(50, 100).ToPosf()))
+	assert.InDelta(t, 0.8, wb.ScaleAt(pctk.NewPos(50, 120).ToPosf()), 0.001)
+	assert.Equal(t, float32(1), wb.ScaleAt(pctk.NewPos(50, 140).ToPosf()))
+	assert.Equal(t, float32(1), wb.ScaleAt(pctk.NewPos(50, 150).ToPosf()))
+}
+
+func TestFindPath_Polygons(t *testing.T) {
+	// A pentagon with a triangle sharing its right edge, and a square touching the triangle in a
+	// single vertex, so it is not reachable.
+	pentagon := pctk.NewWalkBox("pentagon", []pctk.Position{{0, 0}, {4, 0}, {4, 4}, {2, 6}, {0, 4}}, DefaultScale)
+	triangle := pctk.NewWalkBox("triangle", []pctk.Position{{4, 1}, {8, 2}, {4, 3}}, DefaultScale)
+	square := pctk.NewWalkBox("square", []pctk.Position{{8, 2}, {10, 2}, {10, 4}, {8, 4}}, DefaultScale)
+	walkBoxMatrix := pctk.NewWalkBoxMatrix([]*pctk.WalkBox{pentagon, triangle, square})
+
+	path := walkBoxMatrix.FindPath(pctk.NewPos(1, 1), pctk.NewPos(6, 2))
+	assert.Len(t, path, 2)
+	assert.Equal(t, triangle, path[1].Walkbox)
+	assert.Equal(t, pctk.NewPos(6, 2), path[1].Position)
+
+	path = walkBoxMatrix.FindPath(pctk.NewPos(1, 1), pctk.NewPos(9, 3))
+	assert.Equal(t, triangle, path[len(path)-1].Walkbox, "square is not reachable, triangle is the closest")
+}
+
+func TestFindPath_NoWalkBoxes(t *testing.T) {
+	want := []*pctk.WayPoint{{Position: pctk.NewPos(1, 1)}, {Position: pctk.NewPos(6, 2)}}
+	assert.Equal(t, want, pctk.NewWalkBoxMatrix(nil).FindPath(pctk.NewPos(1, 1), pctk.NewPos(6, 2)))
+
+	var walkBoxMatrix *pctk.WalkBoxMatrix
+	assert.Equal(t, want, walkBoxMatrix.FindPath(pctk.NewPos(1, 1), pctk.NewPos(6, 2)))
+}
+
+func TestFindPath_Corners(t *testing.T) {
+	/*
+		An L-shaped corridor, where actors turn at the inner corner:
+
+		  +-----------+---+
+		  |   box0        |
+		  +-----------+---+
+		              |   |
+		              |box|
+		              | 1 |
+		              +---+
+	*/
+	box0 := pctk.NewWalkBox("box0", []pctk.Position{{0, 0}, {10, 0}, {10, 2}, {0, 2}}, DefaultScale)
+	box1 := pctk.NewWalkBox("box1", []pctk.Position{{8, 2}, {10, 2}, {10, 10}, {8, 10}}, DefaultScale)
+	walkBoxMatrix := pctk.NewWalkBoxMatrix([]*pctk.WalkBox{box0, box1})
+
+	path := walkBoxMatrix.FindPath(pctk.NewPos(1, 1), pctk.NewPos(9, 9))
+	positions := make([]pctk.Position, len(path))
+	for i, wp := range path {
+		positions[i] = wp.Position
+	}
+	assert.Equal(t, []pctk.Position{{1, 1}, {8, 2}, {9, 9}}, positions)
+	assert.Equal(t, box1, path[1].Walkbox)
+
+	path = walkBoxMatrix.FindPath(pctk.NewPos(9, 9), pctk.NewPos(9, 1))
+	assert.Len(t, path, 2, "actors walk straight when nothing but walkable space lies in between")
+}
+
+func TestNewWalkBox_TooFewVertices(t *testing.T) {
+	assert.Panics(t, func() {
+		pctk.NewWalkBox(DefaultWalkBoxID, []pctk.Position{{0, 0}, {4, 0}}, DefaultScale)
+	})
+}